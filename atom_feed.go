@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// The Atom elements are bound to the Atom namespace: an unqualified tag would
+// also match extension elements such as media:content or media:title, which
+// then overwrite the entry's own.
+type AtomFeed struct {
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title    AtomText    `xml:"http://www.w3.org/2005/Atom title"`
+	Subtitle AtomText    `xml:"http://www.w3.org/2005/Atom subtitle"`
+	Icon     string      `xml:"http://www.w3.org/2005/Atom icon"`
+	Logo     string      `xml:"http://www.w3.org/2005/Atom logo"`
+	Links    []AtomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Entries  []AtomEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"http://www.w3.org/2005/Atom id"`
+	Title     AtomText   `xml:"http://www.w3.org/2005/Atom title"`
+	Links     []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Summary   AtomText   `xml:"http://www.w3.org/2005/Atom summary"`
+	Content   AtomText   `xml:"http://www.w3.org/2005/Atom content"`
+	Published string     `xml:"http://www.w3.org/2005/Atom published"`
+	Updated   string     `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AtomText is an Atom text construct. Plain text and escaped HTML arrive as
+// character data, while type="xhtml" carries inline markup.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+func parseAtom(data []byte) (*ParsedFeed, error) {
+	var feed AtomFeed
+	err := xml.Unmarshal(data, &feed)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedFeed{
+		Format:      "atom",
+		Title:       feed.Title.String(),
+		Link:        atomAlternateLink(feed.Links),
+		Description: feed.Subtitle.String(),
+		Icon:        firstNonEmpty(feed.Icon, feed.Logo),
+		Language:    feed.Lang,
+	}
+
+	for _, entry := range feed.Entries {
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		item := FeedItem{
+			GUID:        strings.TrimSpace(entry.ID),
+			Title:       entry.Title.String(),
+			Link:        atomAlternateLink(entry.Links),
+			Description: entry.Summary.String(),
+			Content:     entry.Content.String(),
+			PubDate:     strings.TrimSpace(pubDate),
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				item.addEnclosure(Enclosure{
+					URL:    link.Href,
+					Type:   link.Type,
+					Length: parseLength(link.Length),
+				})
+			}
+		}
+
+		parsed.Items = append(parsed.Items, item)
+	}
+
+	return parsed, nil
+}
+
+func atomAlternateLink(links []AtomLink) string {
+	link := ""
+	for _, l := range links {
+		if l.Rel != "" && l.Rel != "alternate" {
+			continue
+		}
+		if l.Type == "" || l.Type == "text/html" {
+			return l.Href
+		}
+		if link == "" {
+			link = l.Href
+		}
+	}
+	return link
+}
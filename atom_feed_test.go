@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestParseAtom(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *ParsedFeed
+	}{
+		{
+			"atom.xml",
+			&ParsedFeed{
+				Format:      "atom",
+				Title:       "Example Blog",
+				Link:        "https://blog.example.com/",
+				Description: "Notes &amp; essays",
+				Icon:        "https://blog.example.com/favicon.ico",
+				Language:    "en-US",
+				Items: []FeedItem{
+					{
+						GUID:        "tag:blog.example.com,2024:ship-it",
+						Title:       "Ship it &amp; see",
+						Link:        "https://blog.example.com/2024/ship-it",
+						Description: "Why small releases win.",
+						Content:     `<div xmlns="http://www.w3.org/1999/xhtml"><p>Small releases <em>win</em>.</p></div>`,
+						PubDate:     "2024-03-01T12:00:00Z",
+					},
+					{
+						GUID:    "tag:blog.example.com,2024:episode-12",
+						Title:   "Episode 12",
+						Link:    "https://blog.example.com/2024/episode-12",
+						Content: "<p>Show notes</p>",
+						PubDate: "2024-02-20T08:00:00+01:00",
+						Enclosures: []Enclosure{
+							{URL: "https://cdn.example.com/ep12.mp3", Type: "audio/mpeg", Length: 1234567},
+						},
+					},
+				},
+			},
+		},
+		{
+			// Media RSS and Dublin Core elements share local names with
+			// Atom's and must not replace them.
+			"atom_media.xml",
+			&ParsedFeed{
+				Format: "atom",
+				Title:  "Photo Journal",
+				Link:   "https://photos.example.org/",
+				Items: []FeedItem{
+					{
+						GUID:        "https://photos.example.org/p/harbour",
+						Title:       "Harbour at dusk",
+						Link:        "https://photos.example.org/p/harbour",
+						Description: "A long exposure.",
+						Content:     "<p>body</p>",
+						PubDate:     "2024-05-10T18:00:00Z",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := parseAtom(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseAtom: %v", err)
+			}
+			checkParsedFeed(t, got, tt.want)
+		})
+	}
+}
+
+func TestAtomAlternateLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []AtomLink
+		want  string
+	}{
+		{"none", nil, ""},
+		{"implicit alternate", []AtomLink{{Href: "https://a/"}}, "https://a/"},
+		{
+			"skips other relations",
+			[]AtomLink{{Href: "https://a/feed", Rel: "self"}, {Href: "https://a/", Rel: "alternate"}},
+			"https://a/",
+		},
+		{
+			"prefers html",
+			[]AtomLink{{Href: "https://a/x.json", Type: "application/json"}, {Href: "https://a/x", Type: "text/html"}},
+			"https://a/x",
+		},
+		{
+			"falls back to first alternate",
+			[]AtomLink{{Href: "https://a/x.json", Type: "application/json"}, {Href: "https://a/x.txt", Type: "text/plain"}},
+			"https://a/x.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := atomAlternateLink(tt.links); got != tt.want {
+				t.Errorf("atomAlternateLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package main
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+)
 
 type command struct {
 	name      string
@@ -8,24 +13,56 @@ type command struct {
 }
 
 type commands struct {
-	commands map[string]func(*state, command) error
+	commands map[string]func(context.Context, *state, command) error
+	// lists holds the commands that print through renderTable and so honor
+	// --output.
+	lists map[string]bool
 }
 
-func (c *commands) register(name string, f func(*state, command) error) {
+func (c *commands) register(name string, f func(context.Context, *state, command) error) {
 	c.commands[name] = f
 }
 
-func (c *commands) run(s *state, cmd command) error {
+// registerList registers a command whose output can be chosen with --output.
+func (c *commands) registerList(name string, f func(context.Context, *state, command) error) {
+	c.register(name, f)
+	c.lists[name] = true
+}
+
+func (c *commands) run(ctx context.Context, s *state, cmd command) error {
 	exsits, ok := c.commands[cmd.name]
 
 	if !ok {
 		return errors.New("no command found")
 	}
+	if s.output != outputText && !c.lists[cmd.name] {
+		return fmt.Errorf("%s does not support --output %s", cmd.name, s.output)
+	}
 
-	err := exsits(s, cmd)
+	err := exsits(ctx, s, cmd)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// parseFlags parses flags wherever they appear among the arguments, so
+// "agg 1m --concurrency 4" works as well as "agg --concurrency 4 1m", and
+// returns the remaining positional arguments.
+func parseFlags(fs *flag.FlagSet, arguments []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = fs.Args()
+		if len(arguments) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/RafaelTauschek/internal/markup"
+)
+
+type feedCandidate struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+// feedLinkTypes are the <link type> values that advertise a feed. Plain
+// application/json is left out: WordPress uses it to advertise its REST API
+// on every page.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+// feedResponse is what a URL returned when it turned out to serve a feed
+// itself, so that the feed does not have to be downloaded a second time.
+type feedResponse struct {
+	StatusCode   int
+	ContentType  string
+	ETag         string
+	LastModified string
+	Body         []byte
+}
+
+// resolveFeedURL returns the URL of the feed behind rawURL. A URL that
+// already serves a feed is returned as is, together with the response; for an
+// HTML page the advertised feeds are discovered, choose picks one when there
+// are several, and the response is nil.
+func resolveFeedURL(ctx context.Context, rawURL string, choose func([]feedCandidate) (feedCandidate, error)) (string, *feedResponse, error) {
+	req, err := newFeedRequest(ctx, rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", nil, &httpStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !isHTML(contentType, data) {
+		return rawURL, &feedResponse{
+			StatusCode:   resp.StatusCode,
+			ContentType:  contentType,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         data,
+		}, nil
+	}
+
+	candidates := discoverFeeds(resp.Request.URL, data)
+	switch len(candidates) {
+	case 0:
+		return "", nil, fmt.Errorf("%s is a web page that does not advertise any feeds", rawURL)
+	case 1:
+		return candidates[0].URL, nil, nil
+	}
+
+	candidate, err := choose(candidates)
+	if err != nil {
+		return "", nil, err
+	}
+	return candidate.URL, nil, nil
+}
+
+func isHTML(contentType string, data []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			return true
+		}
+	}
+
+	head := bytes.ToLower(bytes.TrimSpace(data))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+// discoverFeeds lists the feeds a page advertises through
+// <link rel="alternate" type="..."> elements, resolved against the page URL
+// or its <base href>.
+func discoverFeeds(pageURL *url.URL, page []byte) []feedCandidate {
+	base := pageURL
+	var candidates []feedCandidate
+	seen := map[string]bool{}
+
+	for _, token := range markup.Tokenize(string(page)) {
+		if token.Type != markup.StartTagToken && token.Type != markup.SelfClosingTagToken {
+			continue
+		}
+
+		switch token.Data {
+		case "base":
+			if href, err := pageURL.Parse(token.AttrValue("href")); err == nil {
+				base = href
+			}
+		case "link":
+			if !hasToken(token.AttrValue("rel"), "alternate") {
+				continue
+			}
+			linkType := strings.ToLower(strings.TrimSpace(token.AttrValue("type")))
+			if !feedLinkTypes[linkType] {
+				continue
+			}
+
+			href, err := base.Parse(strings.TrimSpace(token.AttrValue("href")))
+			if err != nil || seen[href.String()] {
+				continue
+			}
+			seen[href.String()] = true
+
+			candidates = append(candidates, feedCandidate{
+				URL:   href.String(),
+				Title: strings.TrimSpace(token.AttrValue("title")),
+				Type:  linkType,
+			})
+		case "body":
+			return candidates
+		}
+	}
+
+	return candidates
+}
+
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(strings.ToLower(list)) {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
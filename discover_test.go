@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverFeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []feedCandidate
+	}{
+		{
+			"wordpress page",
+			`<!DOCTYPE html><html><head>
+<link rel="alternate" type="application/rss+xml" title="Example &raquo; Feed" href="https://www.example.com/feed/" />
+<link rel="alternate" type="application/rss+xml" title="Example &raquo; Comments Feed" href="https://www.example.com/comments/feed/" />
+<link rel="https://api.w.org/" href="https://www.example.com/wp-json/" />
+<link rel="alternate" title="JSON" type="application/json" href="https://www.example.com/wp-json/wp/v2/pages/7" />
+<link rel="alternate" type="application/json+oembed" href="https://www.example.com/wp-json/oembed/1.0/embed?url=x" />
+</head><body></body></html>`,
+			[]feedCandidate{
+				{URL: "https://www.example.com/feed/", Title: "Example » Feed", Type: "application/rss+xml"},
+				{URL: "https://www.example.com/comments/feed/", Title: "Example » Comments Feed", Type: "application/rss+xml"},
+			},
+		},
+		{
+			"json feed",
+			`<html><head><link rel="alternate" type="application/feed+json" href="/feed.json"></head></html>`,
+			[]feedCandidate{
+				{URL: "https://www.example.com/feed.json", Type: "application/feed+json"},
+			},
+		},
+		{
+			"relative to base",
+			`<html><head><base href="https://cdn.example.com/blog/"><link rel="Alternate Feed" type="Application/Atom+XML" href="atom.xml"></head></html>`,
+			[]feedCandidate{
+				{URL: "https://cdn.example.com/blog/atom.xml", Type: "application/atom+xml"},
+			},
+		},
+		{
+			"duplicates and body links are ignored",
+			`<html><head><link rel="alternate" type="application/rss+xml" href="/rss"><link rel="alternate" type="application/rss+xml" href="https://www.example.com/rss"></head>
+<body><link rel="alternate" type="application/atom+xml" href="/atom"></body></html>`,
+			[]feedCandidate{
+				{URL: "https://www.example.com/rss", Type: "application/rss+xml"},
+			},
+		},
+		{
+			"no feeds",
+			`<html><head><link rel="stylesheet" href="/style.css"></head></html>`,
+			nil,
+		},
+	}
+
+	pageURL, err := url.Parse("https://www.example.com/about/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discoverFeeds(pageURL, []byte(tt.page))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discoverFeeds()\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ParsedFeed struct {
+	Format      string
+	Title       string
+	Link        string
+	Description string
+	Icon        string
+	Language    string
+	Items       []FeedItem
+}
+
+type FeedItem struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	Content     string
+	PubDate     string
+	Enclosures  []Enclosure
+}
+
+// Enclosure is a media file attached to an item, such as a podcast episode.
+// Length is in bytes and Duration in seconds; zero means unknown.
+type Enclosure struct {
+	URL      string
+	Type     string
+	Length   int64
+	Duration int
+}
+
+// Key identifies the item within its feed: the GUID the feed assigned, or the
+// link for feeds that do not assign any.
+func (item FeedItem) Key() string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	return item.Link
+}
+
+// Hash fingerprints the parts of an item that are stored on its post, so an
+// edited item can be told apart from one that is merely fetched again.
+func (item FeedItem) Hash() string {
+	h := sha256.New()
+	for _, field := range []string{item.Title, item.Link, item.Description, item.Content, item.PubDate} {
+		io.WriteString(h, field)
+		h.Write([]byte{0})
+	}
+	for _, enclosure := range item.Enclosures {
+		fmt.Fprintf(h, "%s %s %d %d", enclosure.URL, enclosure.Type, enclosure.Length, enclosure.Duration)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addEnclosure appends an enclosure unless one with the same URL is already
+// known, in which case missing details are filled in from the new one.
+func (item *FeedItem) addEnclosure(enclosure Enclosure) {
+	enclosure.URL = strings.TrimSpace(enclosure.URL)
+	if enclosure.URL == "" {
+		return
+	}
+
+	for i := range item.Enclosures {
+		existing := &item.Enclosures[i]
+		if existing.URL != enclosure.URL {
+			continue
+		}
+		if existing.Type == "" {
+			existing.Type = enclosure.Type
+		}
+		if existing.Length == 0 {
+			existing.Length = enclosure.Length
+		}
+		if existing.Duration == 0 {
+			existing.Duration = enclosure.Duration
+		}
+		return
+	}
+
+	item.Enclosures = append(item.Enclosures, enclosure)
+}
+
+// parseLength reads a byte count attribute, treating junk as unknown.
+func parseLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseMediaDuration reads durations written as seconds ("3600", "3600.5")
+// or as clock time ("1:00:00", "59:30").
+func parseMediaDuration(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	seconds := 0
+	for _, part := range strings.Split(s, ":") {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0
+		}
+		seconds = seconds*60 + int(value)
+	}
+	return seconds
+}
+
+// fetchResult is the outcome of a conditional fetch. Feed is nil when the
+// server answered 304 Not Modified. StatusCode is set whenever a response
+// arrived, including for failed fetches.
+type fetchResult struct {
+	Feed         *ParsedFeed
+	NotModified  bool
+	StatusCode   int
+	Bytes        int
+	ETag         string
+	LastModified string
+}
+
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %s", e.Status)
+}
+
+func fetchFeed(ctx context.Context, feedURL, etag, lastModified string) (fetchResult, error) {
+
+	req, err := newFeedRequest(ctx, feedURL)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	if etag != "" {
+		req.Header.Add("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Add("If-Modified-Since", lastModified)
+	}
+	client := &http.Client{}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	defer resp.Body.Close()
+
+	result := fetchResult{
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		if result.ETag == "" {
+			result.ETag = etag
+		}
+		if result.LastModified == "" {
+			result.LastModified = lastModified
+		}
+		return result, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, &httpStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	result.Bytes = len(data)
+	if err != nil {
+		return result, err
+	}
+
+	result.Feed, err = parseFeed(data, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func newFeedRequest(ctx context.Context, feedURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", "gator")
+	req.Header.Add("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, application/json;q=0.8, */*;q=0.5")
+	return req, nil
+}
+
+func parseFeed(data []byte, contentType string) (*ParsedFeed, error) {
+	feed, err := decodeFeed(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+	feed.Link = strings.TrimSpace(feed.Link)
+	feed.Icon = strings.TrimSpace(feed.Icon)
+	feed.Language = strings.TrimSpace(feed.Language)
+
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+	}
+
+	return feed, nil
+}
+
+func decodeFeed(data []byte, contentType string) (*ParsedFeed, error) {
+	if isJSONFeed(contentType, data) {
+		return parseJSONFeed(data)
+	}
+
+	root, err := xmlRootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	case "RDF":
+		return parseRDF(data)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root)
+	}
+}
+
+func xmlRootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", errors.New("no root element found")
+		}
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func checkParsedFeed(t *testing.T, got, want *ParsedFeed) {
+	t.Helper()
+	gotItems, wantItems := got.Items, want.Items
+	gotFeed, wantFeed := *got, *want
+	gotFeed.Items, wantFeed.Items = nil, nil
+	if !reflect.DeepEqual(gotFeed, wantFeed) {
+		t.Errorf("feed\n got %+v\nwant %+v", gotFeed, wantFeed)
+	}
+
+	if len(gotItems) != len(wantItems) {
+		t.Fatalf("got %d items, want %d", len(gotItems), len(wantItems))
+	}
+	for i := range wantItems {
+		if !reflect.DeepEqual(gotItems[i], wantItems[i]) {
+			t.Errorf("item %d\n got %+v\nwant %+v", i, gotItems[i], wantItems[i])
+		}
+	}
+}
+
+func TestDecodeFeed(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		contentType string
+		format      string
+		items       int
+	}{
+		{"atom.xml", "application/atom+xml", "atom", 2},
+		{"atom_media.xml", "application/xml", "atom", 1},
+		{"rss.xml", "application/rss+xml; charset=utf-8", "rss", 2},
+		{"rss_podcast.xml", "text/xml", "rss", 2},
+		{"rdf.xml", "application/rdf+xml", "rdf", 2},
+		{"feed.json", "application/feed+json", "json", 2},
+		// Servers often get the content type wrong; the body decides.
+		{"atom.xml", "text/html", "atom", 2},
+		{"feed.json", "text/plain", "json", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+" as "+tt.contentType, func(t *testing.T) {
+			feed, err := decodeFeed(readFixture(t, tt.fixture), tt.contentType)
+			if err != nil {
+				t.Fatalf("decodeFeed: %v", err)
+			}
+			if feed.Format != tt.format {
+				t.Errorf("Format = %q, want %q", feed.Format, tt.format)
+			}
+			if len(feed.Items) != tt.items {
+				t.Errorf("got %d items, want %d", len(feed.Items), tt.items)
+			}
+		})
+	}
+}
+
+func TestDecodeFeedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		contentType string
+		wantErr     string
+	}{
+		{"json api", `{"data":[1,2,3]}`, "application/json", "not a JSON Feed"},
+		{"html page", "<!DOCTYPE html><html><body>Not a feed</body></html>", "text/html", "unsupported feed format: <html>"},
+		{"other xml", `<?xml version="1.0"?><urlset></urlset>`, "application/xml", "unsupported feed format: <urlset>"},
+		{"empty", "", "", "no root element found"},
+		{"text", "just some text", "text/plain", "no root element found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeFeed([]byte(tt.input), tt.contentType)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("decodeFeed(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFeedUnescapesText(t *testing.T) {
+	feed, err := parseFeed(readFixture(t, "atom.xml"), "application/atom+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Notes & essays"; feed.Description != want {
+		t.Errorf("Description = %q, want %q", feed.Description, want)
+	}
+	if want := "Ship it & see"; feed.Items[0].Title != want {
+		t.Errorf("Items[0].Title = %q, want %q", feed.Items[0].Title, want)
+	}
+}
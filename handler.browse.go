@@ -2,39 +2,224 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/RafaelTauschek/internal/database"
+	"github.com/RafaelTauschek/internal/dateparse"
+	"github.com/RafaelTauschek/internal/markup"
+	"github.com/google/uuid"
 )
 
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2
+const (
+	summaryLength = 280
+	textWidth     = 80
+)
+
+func handlerBrowse(ctx context.Context, s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	full := fs.Bool("full", false, "print the full body of each post instead of a summary")
+	unread := fs.Bool("unread", true, "only list posts that have not been read yet")
+	all := fs.Bool("all", false, "include posts that have already been read, same as --unread=false")
+	starred := fs.Bool("starred", false, "only list starred posts, read or not")
+	feedArg := fs.String("feed", "", "only list posts from this feed, by url or name")
+	since := fs.String("since", "", "only list posts published at or after this date, or this long ago (e.g. 2024-05-01, 36h, 7d)")
+	until := fs.String("until", "", "only list posts published before this date, or this long ago")
+	sortBy := fs.String("sort", "published", "order posts by published, fetched or feed")
+	limit := fs.Int("limit", 2, "maximum number of posts to list")
+	offset := fs.Int("offset", 0, "skip this many posts, to page through the results")
 
-	if len(cmd.arguments) == 1 {
-		cmdLimit, err := strconv.Atoi(cmd.arguments[0])
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) > 1 {
+		return errors.New("too many arguments provided")
+	}
+
+	// The limit used to be positional, which still works.
+	if len(arguments) == 1 {
+		cmdLimit, err := strconv.Atoi(arguments[0])
 		if err != nil {
 			return err
 		}
-		limit = cmdLimit
+		*limit = cmdLimit
+	}
+	if *limit < 1 || *offset < 0 {
+		return errors.New("limit must be positive and offset must not be negative")
 	}
 
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-	})
+	switch *sortBy {
+	case "published", "fetched", "feed":
+	default:
+		return fmt.Errorf("unknown sort order %q, use published, fetched or feed", *sortBy)
+	}
+
+	params := database.BrowsePostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: !*unread || *all || *starred,
+		StarredOnly: *starred,
+		SortBy:      *sortBy,
+		MaxPosts:    int32(*limit),
+		SkipPosts:   int32(*offset),
+	}
+
+	if *feedArg != "" {
+		feedID, err := findFollowedFeed(ctx, s, user, *feedArg)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	if *since != "" {
+		t, err := parseTimeBound(*since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseTimeBound(*until)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	posts, err := s.db.BrowsePostsForUser(ctx, params)
 	if err != nil {
 		return err
 	}
 
-	for _, post := range posts {
+	columns := []string{"id", "title", "url", "feed_name", "published_at", "read_at", "starred_at", "summary", "enclosures"}
+	if *full {
+		columns = append(columns, "content_html")
+	}
+	t := newTable(columns...)
+	postEnclosures := make([][]database.Enclosure, len(posts))
+	for i, post := range posts {
+		postEnclosures[i], err = s.db.GetEnclosuresForPost(ctx, post.ID)
+		if err != nil {
+			return err
+		}
+
+		enclosureURLs := make([]string, 0, len(postEnclosures[i]))
+		for _, enclosure := range postEnclosures[i] {
+			enclosureURLs = append(enclosureURLs, enclosure.Url)
+		}
+		values := []any{
+			post.ID, post.Title, post.Url, post.FeedName, post.PublishedAt, post.ReadAt, post.StarredAt,
+			summarize(markup.Plain(postSummary(post.Description, post.Content)), summaryLength),
+			enclosureURLs,
+		}
+		if *full {
+			values = append(values, postBody(post.Description, post.Content))
+		}
+		t.add(values...)
+	}
+
+	err = renderTable(s, t, func(i int) {
+		post := posts[i]
 		fmt.Println("***********************")
+		fmt.Printf("ID: %s\n", post.ID)
 		fmt.Printf("Title: %s\n", post.Title)
-		fmt.Printf("Description: %s\n", post.Description.String)
+		if *full {
+			fmt.Printf("\n%s\n\n", markup.Text(postBody(post.Description, post.Content), textWidth))
+		} else {
+			fmt.Printf("Description: %s\n", summarize(markup.Plain(postSummary(post.Description, post.Content)), summaryLength))
+		}
 		fmt.Printf("Link: %s\n", post.Url)
+		for _, enclosure := range postEnclosures[i] {
+			fmt.Printf("Enclosure: %s\n", describeEnclosure(enclosure))
+		}
 		fmt.Printf("From: %s\n", post.PublishedAt.Time)
+		if post.ReadAt.Valid {
+			fmt.Printf("Read: %s\n", post.ReadAt.Time)
+		}
+		if post.StarredAt.Valid {
+			fmt.Printf("Starred: %s\n", post.StarredAt.Time)
+		}
 		fmt.Println("***********************")
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.output == outputText && len(posts) == *limit {
+		fmt.Printf("More posts with --offset %d\n", *offset+*limit)
 	}
 
 	return nil
 }
+
+// findFollowedFeed looks up one of the user's feeds by url, or failing that by
+// name.
+func findFollowedFeed(ctx context.Context, s *state, user database.User, feedArg string) (uuid.UUID, error) {
+	following, err := s.db.GetFeedFollowForUser(ctx, user.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, follow := range following {
+		if follow.FeedUrl == feedArg {
+			return follow.FeedID, nil
+		}
+	}
+	for _, follow := range following {
+		if strings.EqualFold(follow.FeedsName, feedArg) {
+			return follow.FeedID, nil
+		}
+	}
+	return uuid.Nil, fmt.Errorf("you do not follow a feed with url or name %s", feedArg)
+}
+
+// parseTimeBound reads an absolute date, or a duration such as "36h" or "7d"
+// counted back from now.
+func parseTimeBound(s string) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().UTC().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	return dateparse.Parse(s)
+}
+
+// postSummary prefers the feed's own summary and falls back to the body.
+func postSummary(description, content sql.NullString) string {
+	if description.String != "" {
+		return description.String
+	}
+	return content.String
+}
+
+// postBody prefers the full content and falls back to the summary.
+func postBody(description, content sql.NullString) string {
+	if content.String != "" {
+		return content.String
+	}
+	return description.String
+}
+
+// summarize collapses whitespace and cuts text to at most limit characters
+// on a word boundary.
+func summarize(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
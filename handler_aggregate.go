@@ -4,105 +4,505 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"strings"
+	"net/url"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/RafaelTauschek/internal/database"
+	"github.com/RafaelTauschek/internal/dateparse"
+	"github.com/RafaelTauschek/internal/markup"
 	"github.com/google/uuid"
 )
 
-func handlerAggregate(s *state, cmd command) error {
-	if len(cmd.arguments) != 1 {
+type aggregateOptions struct {
+	workerID        string
+	concurrency     int
+	lease           time.Duration
+	shutdownTimeout time.Duration
+}
+
+func handlerAggregate(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", 1, "number of feeds to fetch in parallel per tick")
+	lease := fs.Duration("lease", 10*time.Minute, "how long a claimed feed stays reserved for this process")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "how long in-flight fetches may run after a shutdown signal")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
 		return errors.New("no arguments provided")
 	}
+	if *concurrency < 1 {
+		return errors.New("concurrency must be at least 1")
+	}
+	if *lease <= 0 {
+		return errors.New("lease must be positive")
+	}
 
-	timeInterval, err := time.ParseDuration(cmd.arguments[0])
+	hostname, err := os.Hostname()
 	if err != nil {
 		return err
 	}
+	opts := aggregateOptions{
+		workerID:        fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		concurrency:     *concurrency,
+		lease:           *lease,
+		shutdownTimeout: *shutdownTimeout,
+	}
 
-	fmt.Printf("Collecting feeds every %s\n", timeInterval)
+	timeInterval, err := time.ParseDuration(arguments[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Collecting feeds every %s with %d workers\n", timeInterval, *concurrency)
 	ticker := time.NewTicker(timeInterval)
 	defer ticker.Stop()
 
-	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+	for {
+		err := scrapeFeeds(ctx, s, opts)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Couldn't scrape feeds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped collecting feeds")
+			return nil
+		case <-ticker.C:
+		}
 	}
 }
 
-func handlerFeeds(s *state, cmd command) error {
-	feeds, err := s.db.GetFeeds(context.Background())
+func handlerFeeds(ctx context.Context, s *state, cmd command) error {
+	feeds, err := s.db.GetFeeds(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, feed := range feeds {
-		name, err := s.db.GetUserName(context.Background(), feed.UserID)
+	t := newTable("name", "url", "user_name", "title", "description", "site_link", "icon_url", "language", "last_fetched_at")
+	userNames := make([]string, len(feeds))
+	for i, feed := range feeds {
+		userNames[i], err = s.db.GetUserName(ctx, feed.UserID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("%s at %s by %s\n", feed.Name, feed.Url, name)
+		t.add(feed.Name, feed.Url, userNames[i], feed.ChannelTitle, feed.Description, feed.SiteLink, feed.IconUrl, feed.Language, feed.LastFechtedAt)
+	}
+
+	return renderTable(s, t, func(i int) {
+		feed := feeds[i]
+		fmt.Printf("%s at %s by %s\n", feed.Name, feed.Url, userNames[i])
+		if feed.ChannelTitle.Valid && feed.ChannelTitle.String != feed.Name {
+			fmt.Printf("  Title: %s\n", feed.ChannelTitle.String)
+		}
+		if feed.Description.Valid {
+			fmt.Printf("  Description: %s\n", summarize(feed.Description.String, summaryLength))
+		}
+		if feed.SiteLink.Valid {
+			fmt.Printf("  Site: %s\n", feed.SiteLink.String)
+		}
+		if feed.IconUrl.Valid {
+			fmt.Printf("  Icon: %s\n", feed.IconUrl.String)
+		}
+		if feed.Language.Valid {
+			fmt.Printf("  Language: %s\n", feed.Language.String)
+		}
+	})
+}
+
+// scrapeFeeds leases up to opts.concurrency of the stalest feeds and fetches
+// them in parallel. The lease keeps other agg processes away from a feed
+// until it is marked fetched, and lapses on its own if this process dies.
+// Leases are timed by the database clock so that hosts whose clocks or time
+// zones differ agree on when one runs out, and each fetch is cut off when its
+// lease does, before another process may claim the feed. That also bounds a
+// tick: one hung server holds up the next by at most the lease.
+//
+// Once ctx is cancelled no further feeds are started; fetches already in
+// flight get opts.shutdownTimeout to finish before they are aborted too.
+func scrapeFeeds(ctx context.Context, s *state, opts aggregateOptions) error {
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(opts.shutdownTimeout, cancelWork)
+	})
+	defer stop()
+
+	feeds, err := s.db.ClaimFeedsToFetch(ctx, database.ClaimFeedsToFetchParams{
+		LockedBy: sql.NullString{
+			String: opts.workerID,
+			Valid:  true,
+		},
+		LeaseSeconds: opts.lease.Seconds(),
+		MaxFeeds:     int32(opts.concurrency),
+	})
+	if err != nil {
+		return err
+	}
+
+	jobs := make(chan database.Feed)
+	var wg sync.WaitGroup
+	for range min(opts.concurrency, len(feeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range jobs {
+				feedCtx, cancel := context.WithTimeout(workCtx, opts.lease)
+				err := scrapeFeed(feedCtx, s, feed)
+				cancel()
+				if err != nil {
+					log.Printf("Couldn't fetch %s: %v", feed.Url, err)
+					releaseFeed(ctx, s, feed)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i, feed := range feeds {
+		select {
+		case jobs <- feed:
+		case <-ctx.Done():
+			for _, pending := range feeds[i:] {
+				releaseFeed(ctx, s, pending)
+			}
+			break dispatch
+		}
 	}
+	close(jobs)
+	wg.Wait()
 
 	return nil
 }
 
-func scrapeFeeds(s *state) error {
-	nextFeed, err := s.db.GetNextFeedToFetch(context.Background())
+// releaseFeed hands a leased feed back early. It runs even after ctx is
+// cancelled so an interrupted agg does not leave feeds reserved.
+func releaseFeed(ctx context.Context, s *state, feed database.Feed) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	err := s.db.ReleaseFeedLease(ctx, database.ReleaseFeedLeaseParams{
+		ID:       feed.ID,
+		LockedBy: feed.LockedBy,
+	})
+	if err != nil {
+		log.Printf("Couldn't release lease on %s: %v", feed.Url, err)
+	}
+}
+
+// errLeaseLost reports a feed whose lease ran out, and may have been taken
+// over by another process, before the fetch could be recorded.
+var errLeaseLost = errors.New("lease expired before the fetch was recorded")
+
+const (
+	fetchBackoffBase = time.Minute
+	fetchBackoffMax  = 24 * time.Hour
+)
+
+// fetchBackoff returns how long a feed that has failed failures times in a
+// row should rest before the next attempt.
+func fetchBackoff(failures int32) time.Duration {
+	backoff := fetchBackoffBase
+	for i := int32(1); i < failures && backoff < fetchBackoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, fetchBackoffMax)
+}
+
+func recordFetchFailure(ctx context.Context, s *state, feed database.Feed, statusCode int, fetchErr error) error {
+	now := time.Now()
+	_, err := s.db.MarkFeedFetchFailed(ctx, database.MarkFeedFetchFailedParams{
+		LastFechtedAt: sql.NullTime{
+			Time:  now,
+			Valid: true,
+		},
+		UpdatedAt: now,
+		LastFetchStatus: sql.NullInt32{
+			Int32: int32(statusCode),
+			Valid: statusCode != 0,
+		},
+		LastFetchError: sql.NullString{
+			String: fetchErr.Error(),
+			Valid:  true,
+		},
+		BackoffSeconds: fetchBackoff(feed.ConsecutiveFailures + 1).Seconds(),
+		ID:             feed.ID,
+		LockedBy:       feed.LockedBy,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errLeaseLost
+	}
+	return err
+}
+
+func scrapeFeed(ctx context.Context, s *state, feed database.Feed) error {
+	startedAt := time.Now()
+	result, err := fetchFeed(ctx, feed.Url, feed.Etag.String, feed.LastModified.String)
 	if err != nil {
+		// An aborted shutdown fetch says nothing about the feed's health.
+		// One that outlived its lease does: it is recorded as a failure so
+		// that backoff keeps a hung server from holding up every tick.
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return err
+		}
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if recordErr := recordFetchFailure(recordCtx, s, feed, result.StatusCode, err); recordErr != nil {
+			log.Printf("Couldn't record failure for %s: %v", feed.Url, recordErr)
+		}
+		recordFeedFetch(recordCtx, s, feed, startedAt, result, postCounts{}, err)
 		return err
 	}
 
-	feed, err := fetchFeed(context.Background(), nextFeed.Url)
+	counts, err := storeFetchResult(ctx, s, feed, startedAt, result)
 	if err != nil {
 		return err
 	}
+	if !result.NotModified {
+		log.Printf("Fetched %s: %d new, %d updated posts", feed.Url, counts.created, counts.updated)
+	}
 
-	_, err = s.db.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
+	return nil
+}
+
+// storeFetchResult saves the posts of a successful fetch, then marks the feed
+// fetched and records the attempt in the feed's history.
+//
+// The feed's ETag and Last-Modified are only kept once every post is saved:
+// otherwise the server would answer the next fetch with 304 and the missing
+// posts would never be stored. A save cut short by shutdown leaves the feed
+// untouched, to be fetched again in full.
+func storeFetchResult(ctx context.Context, s *state, feed database.Feed, startedAt time.Time, result fetchResult) (postCounts, error) {
+	counts := postCounts{}
+	var saveErr error
+	if !result.NotModified {
+		saveErr = saveFeedMetadata(ctx, s, feed, result.Feed)
+		if saveErr == nil {
+			counts, saveErr = savePosts(ctx, s, feed.ID, result.Feed.Items)
+		}
+		if ctx.Err() != nil {
+			return counts, ctx.Err()
+		}
+	}
+
+	etag, lastModified := result.ETag, result.LastModified
+	if saveErr != nil {
+		etag, lastModified = "", ""
+	}
+
+	_, err := s.db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
 		LastFechtedAt: sql.NullTime{
 			Time:  time.Now(),
 			Valid: true,
 		},
 		UpdatedAt: time.Now(),
-		ID:        nextFeed.ID,
+		Etag: sql.NullString{
+			String: etag,
+			Valid:  etag != "",
+		},
+		LastModified: sql.NullString{
+			String: lastModified,
+			Valid:  lastModified != "",
+		},
+		LastFetchStatus: sql.NullInt32{
+			Int32: int32(result.StatusCode),
+			Valid: true,
+		},
+		ID:       feed.ID,
+		LockedBy: feed.LockedBy,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return counts, errLeaseLost
+	}
 	if err != nil {
-		return err
+		return counts, err
 	}
 
-	for _, item := range feed.Channel.Item {
-		publishedAt := sql.NullTime{}
-		if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
-			publishedAt = sql.NullTime{
-				Time:  t,
-				Valid: true,
+	recordFeedFetch(ctx, s, feed, startedAt, result, counts, saveErr)
+
+	return counts, saveErr
+}
+
+// saveFeedMetadata refreshes what the feed says about itself. Relative links
+// are resolved against the feed URL.
+func saveFeedMetadata(ctx context.Context, s *state, feed database.Feed, parsed *ParsedFeed) error {
+	return s.db.UpdateFeedMetadata(ctx, database.UpdateFeedMetadataParams{
+		SiteLink:     nullString(resolveURL(feed.Url, parsed.Link)),
+		ChannelTitle: nullString(markup.Plain(parsed.Title)),
+		Description:  nullString(markup.Plain(parsed.Description)),
+		IconUrl:      nullString(resolveURL(feed.Url, parsed.Icon)),
+		Language:     nullString(parsed.Language),
+		ID:           feed.ID,
+	})
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
+
+func resolveURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+type postCounts struct {
+	created int
+	updated int
+}
+
+// savePosts upserts the items of a freshly fetched feed. Items whose content
+// hash is unchanged are left alone; the counts report what was written.
+func savePosts(ctx context.Context, s *state, feedID uuid.UUID, items []FeedItem) (postCounts, error) {
+	counts := postCounts{}
+	failed := 0
+	fetchedAt := time.Now().UTC()
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return counts, err
+		}
+
+		publishedAt, parseErr := dateparse.Parse(item.PubDate)
+		inferred := parseErr != nil
+		if inferred {
+			publishedAt = fetchedAt
+		}
+
+		if item.GUID != "" && item.Link != "" && item.GUID != item.Link {
+			err := s.db.AdoptLegacyPost(ctx, database.AdoptLegacyPostParams{
+				Guid:   item.GUID,
+				FeedID: feedID,
+				Url:    item.Link,
+			})
+			if err != nil {
+				log.Printf("Couldn't adopt legacy post %s: %v", item.Link, err)
 			}
 		}
 
-		_, err = s.db.CreatePost(context.Background(), database.CreatePostParams{
+		post, err := s.db.UpsertPost(ctx, database.UpsertPostParams{
+			ID:          uuid.New(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Title:       item.Title,
+			Url:         item.Link,
+			Description: sanitizedText(item.Description),
+			Content:     sanitizedText(item.Content),
+			PublishedAt: sql.NullTime{
+				Time:  publishedAt,
+				Valid: true,
+			},
+			FeedID:              feedID,
+			PublishedAtInferred: inferred,
+			Guid:                item.Key(),
+			ContentHash: sql.NullString{
+				String: item.Hash(),
+				Valid:  true,
+			},
+		})
+
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Couldn't save post: %v", err)
+			failed++
+			continue
+		}
+		saveEnclosures(ctx, s, post.ID, item.Enclosures)
+		if post.Inserted {
+			counts.created++
+		} else {
+			counts.updated++
+		}
+	}
+
+	if failed > 0 {
+		return counts, fmt.Errorf("couldn't save %d of %d posts", failed, len(items))
+	}
+	return counts, nil
+}
+
+func saveEnclosures(ctx context.Context, s *state, postID uuid.UUID, enclosures []Enclosure) {
+	for _, enclosure := range enclosures {
+		err := s.db.UpsertEnclosure(ctx, database.UpsertEnclosureParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
-			Title:     item.Title,
-			Url:       item.Link,
-			Description: sql.NullString{
-				String: item.Description,
-				Valid:  false,
+			PostID:    postID,
+			Url:       enclosure.URL,
+			MimeType: sql.NullString{
+				String: enclosure.Type,
+				Valid:  enclosure.Type != "",
+			},
+			Length: sql.NullInt64{
+				Int64: enclosure.Length,
+				Valid: enclosure.Length > 0,
+			},
+			DurationSeconds: sql.NullInt32{
+				Int32: int32(enclosure.Duration),
+				Valid: enclosure.Duration > 0,
 			},
-			PublishedAt: publishedAt,
-			FeedID:      nextFeed.ID,
 		})
-
 		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique contraint") {
-				continue
-			}
-			log.Printf("Couldn't create post: %v", err)
-			continue
+			log.Printf("Couldn't save enclosure %s: %v", enclosure.URL, err)
 		}
 	}
+}
 
-	return nil
+// sanitizedText strips feed markup down to what is safe to store and show.
+func sanitizedText(s string) sql.NullString {
+	clean := markup.Sanitize(s)
+	return sql.NullString{
+		String: clean,
+		Valid:  clean != "",
+	}
+}
+
+// recordFeedFetch appends an attempt to the feed's fetch history. Failing to
+// write history is logged rather than failing the fetch itself.
+func recordFeedFetch(ctx context.Context, s *state, feed database.Feed, startedAt time.Time, result fetchResult, counts postCounts, fetchErr error) {
+	fetchError := sql.NullString{}
+	if fetchErr != nil {
+		fetchError = sql.NullString{
+			String: fetchErr.Error(),
+			Valid:  true,
+		}
+	}
+
+	err := s.db.CreateFeedFetch(ctx, database.CreateFeedFetchParams{
+		ID:         uuid.New(),
+		FeedID:     feed.ID,
+		FetchedAt:  startedAt,
+		DurationMs: int32(time.Since(startedAt).Milliseconds()),
+		StatusCode: sql.NullInt32{
+			Int32: int32(result.StatusCode),
+			Valid: result.StatusCode != 0,
+		},
+		Bytes:        int64(result.Bytes),
+		NewPosts:     int32(counts.created),
+		UpdatedPosts: int32(counts.updated),
+		Error:        fetchError,
+	})
+	if err != nil {
+		log.Printf("Couldn't record fetch of %s: %v", feed.Url, err)
+	}
 }
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/google/uuid"
+)
+
+func handlerDownload(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to save the enclosure in")
+	index := fs.Int("index", 1, "which enclosure to fetch when a post has several")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("no arguments provided")
+	}
+
+	postID, err := uuid.Parse(arguments[0])
+	if err != nil {
+		return err
+	}
+
+	enclosures, err := s.db.GetEnclosuresForPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if len(enclosures) == 0 {
+		return errors.New("post has no enclosures")
+	}
+	if *index < 1 || *index > len(enclosures) {
+		return fmt.Errorf("post has %d enclosures", len(enclosures))
+	}
+	enclosure := enclosures[*index-1]
+
+	err = os.MkdirAll(*dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(*dir, enclosureFileName(enclosure))
+	if _, err := os.Stat(target); err == nil {
+		fmt.Printf("Already downloaded to %s\n", target)
+		return nil
+	}
+
+	written, err := downloadFile(ctx, enclosure.Url, target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved %s to %s\n", formatBytes(written), target)
+	return nil
+}
+
+// downloadFile fetches url into target. Data is written to target+".part"
+// first, and an existing partial file is resumed with a Range request.
+func downloadFile(ctx context.Context, url, target string) (int64, error) {
+	partial := target + ".part"
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Add("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, _, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			// Appending a range that does not continue the partial file
+			// would corrupt it.
+			return restartDownload(ctx, url, target, file, offset, resp)
+		}
+		fmt.Printf("Resuming at %s\n", formatBytes(offset))
+	case http.StatusOK:
+		// The server ignored the range, so start over.
+		offset = 0
+		err = file.Truncate(0)
+		if err != nil {
+			return 0, err
+		}
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is complete only if it is exactly as long as the
+		// whole file; otherwise it belongs to a different version.
+		_, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || total != offset {
+			return restartDownload(ctx, url, target, file, offset, resp)
+		}
+	default:
+		return 0, &httpStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		_, err = io.Copy(file, resp.Body)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	err = file.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	return size, os.Rename(partial, target)
+}
+
+// restartDownload throws away a partial file that cannot be resumed and
+// downloads the whole file again.
+func restartDownload(ctx context.Context, url, target string, file *os.File, offset int64, resp *http.Response) (int64, error) {
+	if offset == 0 {
+		// Nothing was resumed, so starting over would only repeat this.
+		return 0, fmt.Errorf("unexpected response %s with Content-Range %q", resp.Status, resp.Header.Get("Content-Range"))
+	}
+
+	fmt.Println("Partial download does not match, starting over")
+	err := file.Truncate(0)
+	if err != nil {
+		return 0, err
+	}
+	err = file.Close()
+	if err != nil {
+		return 0, err
+	}
+	return downloadFile(ctx, url, target)
+}
+
+// parseContentRange reads "bytes start-end/total" and "bytes */total". start
+// is -1 for the latter, and total is -1 when the server sent "*".
+func parseContentRange(header string) (start, total int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rangePart, totalPart, found := strings.Cut(strings.TrimSpace(spec), "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	total = -1
+	if totalPart != "*" {
+		n, err := strconv.ParseInt(totalPart, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		total = n
+	}
+
+	if rangePart == "*" {
+		return -1, total, true
+	}
+	first, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// enclosureFileName picks a local name from the enclosure URL, prefixed with
+// the start of the enclosure id so that episodes which all publish an
+// "audio.mp3" do not collide. Without a usable name in the URL it falls back
+// to the full id with an extension guessed from its MIME type.
+func enclosureFileName(enclosure database.Enclosure) string {
+	name := ""
+	if parsed, err := url.Parse(enclosure.Url); err == nil {
+		name = path.Base(parsed.Path)
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == "/" || name == ".." {
+		name = enclosure.ID.String()
+		if extensions, err := mime.ExtensionsByType(enclosure.MimeType.String); err == nil && len(extensions) > 0 {
+			name += extensions[0]
+		}
+		return name
+	}
+
+	return enclosure.ID.String()[:8] + "-" + name
+}
+
+func describeEnclosure(enclosure database.Enclosure) string {
+	var details []string
+	if enclosure.MimeType.Valid {
+		details = append(details, enclosure.MimeType.String)
+	}
+	if enclosure.Length.Valid {
+		details = append(details, formatBytes(enclosure.Length.Int64))
+	}
+	if enclosure.DurationSeconds.Valid {
+		details = append(details, (time.Duration(enclosure.DurationSeconds.Int32) * time.Second).String())
+	}
+
+	if len(details) == 0 {
+		return enclosure.Url
+	}
+	return fmt.Sprintf("%s (%s)", enclosure.Url, strings.Join(details, ", "))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	suffix := ""
+	for _, s := range []string{"KB", "MB", "GB", "TB"} {
+		value /= unit
+		suffix = s
+		if value < unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func handlerExport(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	userName := fs.String("user", s.cfg.CurrentUser, "export the subscriptions of this user")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 0 {
+		return errors.New("usage: export [--user name]")
+	}
+
+	user, err := s.db.GetUser(ctx, *userName)
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", *userName, err)
+	}
+
+	following, err := s.db.GetFeedFollowForUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := make([]opmlSubscription, 0, len(following))
+	for _, follow := range following {
+		subscriptions = append(subscriptions, opmlSubscription{
+			Name:    follow.FeedsName,
+			URL:     follow.FeedUrl,
+			SiteURL: follow.FeedSiteLink.String,
+			Folder:  follow.Folder.String,
+		})
+	}
+
+	doc := OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       fmt.Sprintf("%s subscriptions in gator", user.Name),
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+			OwnerName:   user.Name,
+		},
+		Body: OPMLBody{
+			Outlines: opmlOutlines(subscriptions),
+		},
+	}
+
+	if _, err := os.Stdout.WriteString(xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(os.Stdout)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err = fmt.Println()
+	return err
+}
@@ -1,24 +1,98 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/RafaelTauschek/internal/database"
 	"github.com/google/uuid"
 )
 
-func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.arguments) != 2 {
+func handlerAddFeed(ctx context.Context, s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	force := fs.Bool("force", false, "add the feed even if it cannot be fetched or parsed")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 2 {
 		return errors.New("not enough arguments provided")
 	}
 
-	name := cmd.arguments[0]
-	url := cmd.arguments[1]
+	feed, counts, err := addFeed(ctx, s, user, arguments[0], arguments[1], addFeedOptions{
+		force:  *force,
+		choose: promptFeedChoice,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %s at %s with %d posts\n", feed.Name, feed.Url, counts.created)
+
+	return nil
+}
+
+type addFeedOptions struct {
+	force  bool
+	choose func([]feedCandidate) (feedCandidate, error)
+	folder string
+	// siteURL is used as the feed's website until the feed names one itself.
+	siteURL string
+}
+
+// addFeed resolves rawURL to a feed, checks that it can be fetched and
+// parsed, creates it and follows it for user, and stores its current posts
+// straight away. With force set a feed that fails the check is still added,
+// and its posts are left to the next agg run.
+func addFeed(ctx context.Context, s *state, user database.User, name, rawURL string, opts addFeedOptions) (database.Feed, postCounts, error) {
+	startedAt := time.Now()
+	url, resp, err := resolveFeedURL(ctx, rawURL, opts.choose)
+	if err != nil {
+		if !opts.force {
+			return database.Feed{}, postCounts{}, fmt.Errorf("%w (use --force to add it anyway)", err)
+		}
+		url = rawURL
+	}
+	if url != rawURL {
+		fmt.Printf("Using feed %s\n", url)
+	}
 
-	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+	var result fetchResult
+	switch {
+	case err != nil:
+		// Already reported by the resolver; --force adds the feed anyway.
+	case resp != nil:
+		// rawURL served the feed itself, so parse what was downloaded.
+		result = fetchResult{
+			StatusCode:   resp.StatusCode,
+			Bytes:        len(resp.Body),
+			ETag:         resp.ETag,
+			LastModified: resp.LastModified,
+		}
+		result.Feed, err = parseFeed(resp.Body, resp.ContentType)
+	default:
+		startedAt = time.Now()
+		result, err = fetchFeed(ctx, url, "", "")
+	}
+	if err != nil {
+		if !opts.force {
+			return database.Feed{}, postCounts{}, fmt.Errorf("%s is not a valid feed: %w (use --force to add it anyway)", url, err)
+		}
+		fmt.Printf("Adding %s despite: %v\n", url, err)
+	} else {
+		fmt.Printf("Detected %s feed %q with %d items\n", result.Feed.Format, result.Feed.Title, len(result.Feed.Items))
+	}
+
+	feed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -27,20 +101,68 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 		UserID:    user.ID,
 	})
 	if err != nil {
-		return err
+		return database.Feed{}, postCounts{}, err
 	}
 
-	_, err = s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
 		ID:     uuid.New(),
 		UserID: user.ID,
 		FeedID: feed.ID,
+		Folder: sql.NullString{
+			String: opts.folder,
+			Valid:  opts.folder != "",
+		},
 	})
-
 	if err != nil {
-		return err
+		return database.Feed{}, postCounts{}, err
 	}
 
-	fmt.Printf("%+v\n", feed)
+	counts := postCounts{}
+	if result.Feed != nil {
+		counts, err = storeFetchResult(ctx, s, feed, startedAt, result)
+		if err != nil {
+			return database.Feed{}, postCounts{}, err
+		}
+	}
 
-	return nil
+	if opts.siteURL != "" {
+		err = s.db.SetFeedSiteLinkIfMissing(ctx, database.SetFeedSiteLinkIfMissingParams{
+			SiteLink: nullString(opts.siteURL),
+			ID:       feed.ID,
+		})
+		if err != nil {
+			return database.Feed{}, postCounts{}, err
+		}
+	}
+
+	return feed, counts, nil
+}
+
+// promptFeedChoice asks the user which of several discovered feeds to use.
+func promptFeedChoice(candidates []feedCandidate) (feedCandidate, error) {
+	fmt.Println("This page advertises several feeds:")
+	for i, candidate := range candidates {
+		title := candidate.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		fmt.Printf("  %d. %s [%s] %s\n", i+1, title, candidate.Type, candidate.URL)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Pick a feed [1-%d]: ", len(candidates))
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			return feedCandidate{}, errors.New("no feed selected")
+		}
+
+		choice, convErr := strconv.Atoi(strings.TrimSpace(line))
+		if convErr == nil && choice >= 1 && choice <= len(candidates) {
+			return candidates[choice-1], nil
+		}
+		if err != nil {
+			return feedCandidate{}, errors.New("no feed selected")
+		}
+	}
 }
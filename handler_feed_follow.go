@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -9,19 +10,30 @@ import (
 	"github.com/google/uuid"
 )
 
-func handlerFollow(s *state, cmd command, user database.User) error {
+func handlerFollow(ctx context.Context, s *state, cmd command, user database.User) error {
 	if len(cmd.arguments) != 1 {
 		return errors.New("no arguments provided")
 	}
 
 	url := cmd.arguments[0]
 
-	feed, err := s.db.GetFeedByUrl(context.Background(), url)
+	feed, err := s.db.GetFeedByUrl(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The URL may be the site rather than its feed.
+		resolved, _, resolveErr := resolveFeedURL(ctx, url, promptFeedChoice)
+		if resolveErr != nil || resolved == url {
+			return fmt.Errorf("no feed with url %s, add it with addfeed first", url)
+		}
+		feed, err = s.db.GetFeedByUrl(ctx, resolved)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no feed with url %s, add it with addfeed first", resolved)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
-	feedFollow, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	feedFollow, err := s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
 		ID:     uuid.New(),
 		UserID: user.ID,
 		FeedID: feed.ID,
@@ -36,16 +48,19 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
-func handlerFollowing(s *state, cmd command, user database.User) error {
+func handlerFollowing(ctx context.Context, s *state, cmd command, user database.User) error {
 
-	following, err := s.db.GetFeedFollowForUser(context.Background(), user.ID)
+	following, err := s.db.GetFeedFollowForUser(ctx, user.ID)
 	if err != nil {
 		return err
 	}
 
+	t := newTable("feed_name", "feed_url", "folder")
 	for _, feed := range following {
-		fmt.Printf("%s\n", feed.FeedsName)
+		t.add(feed.FeedsName, feed.FeedUrl, feed.Folder)
 	}
 
-	return nil
+	return renderTable(s, t, func(i int) {
+		fmt.Printf("%s\n", following[i].FeedsName)
+	})
 }
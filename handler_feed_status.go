@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/google/uuid"
+)
+
+func handlerFeedStatus(ctx context.Context, s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	limit := fs.Int("limit", 5, "number of recent fetches to show per feed")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	if len(arguments) > 1 {
+		return errors.New("too many arguments provided")
+	}
+
+	feedID := uuid.NullUUID{}
+	if len(arguments) == 1 {
+		feed, err := s.db.GetFeedByUrl(ctx, arguments[0])
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{
+			UUID:  feed.ID,
+			Valid: true,
+		}
+	}
+
+	stats, err := s.db.GetFeedFetchStats(ctx, feedID)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("name", "url", "attempts", "successes", "avg_duration_ms", "new_posts", "consecutive_failures", "next_fetch_at", "last_error")
+	for _, stat := range stats {
+		t.add(stat.Name, stat.Url, stat.Attempts, stat.Successes, stat.AvgDurationMs, stat.NewPosts,
+			stat.ConsecutiveFailures, stat.NextFetchAt, stat.LastFetchError)
+	}
+
+	// The fetch history only appears in text output.
+	history := make([][]database.FeedFetch, len(stats))
+	if s.output == outputText && *limit > 0 {
+		for i, stat := range stats {
+			if stat.Attempts == 0 {
+				continue
+			}
+			history[i], err = s.db.GetFeedFetches(ctx, database.GetFeedFetchesParams{
+				FeedID: stat.ID,
+				Limit:  int32(*limit),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return renderTable(s, t, func(i int) {
+		stat := stats[i]
+		fmt.Printf("%s (%s)\n", stat.Name, stat.Url)
+
+		if stat.Attempts == 0 {
+			fmt.Println("  never fetched")
+			return
+		}
+
+		successRate := float64(stat.Successes) / float64(stat.Attempts) * 100
+		fmt.Printf("  %d attempts, %.1f%% successful, %.0fms on average, %d new posts\n",
+			stat.Attempts, successRate, stat.AvgDurationMs, stat.NewPosts)
+
+		if stat.ConsecutiveFailures > 0 {
+			fmt.Printf("  failing: %d in a row, next attempt after %s\n",
+				stat.ConsecutiveFailures, stat.NextFetchAt.Time.Local().Format(time.DateTime))
+			fmt.Printf("  last error: %s\n", stat.LastFetchError.String)
+		}
+
+		for _, fetch := range history[i] {
+			status := "---"
+			if fetch.StatusCode.Valid {
+				status = fmt.Sprintf("%d", fetch.StatusCode.Int32)
+			}
+
+			outcome := fmt.Sprintf("%d new, %d updated", fetch.NewPosts, fetch.UpdatedPosts)
+			if fetch.Error.Valid {
+				outcome = "error: " + fetch.Error.String
+			}
+
+			fmt.Printf("  %s  %s  %8d bytes  %6dms  %s\n",
+				fetch.FetchedAt.Format(time.DateTime), status, fetch.Bytes, fetch.DurationMs, outcome)
+		}
+	})
+}
@@ -7,18 +7,18 @@ import (
 	"github.com/RafaelTauschek/internal/database"
 )
 
-func handlerUnfollow(s *state, cmd command, user database.User) error {
+func handlerUnfollow(ctx context.Context, s *state, cmd command, user database.User) error {
 	if len(cmd.arguments) != 1 {
 		return errors.New("no arguments provided")
 	}
 
 	url := cmd.arguments[0]
-	feed, err := s.db.GetFeedByUrl(context.Background(), url)
+	feed, err := s.db.GetFeedByUrl(ctx, url)
 	if err != nil {
 		return err
 	}
 
-	err = s.db.UnfollowFeed(context.Background(), database.UnfollowFeedParams{
+	err = s.db.UnfollowFeed(ctx, database.UnfollowFeedParams{
 		UserID: user.ID,
 		FeedID: feed.ID,
 	})
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/google/uuid"
+)
+
+func handlerImport(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("usage: import <file.opml>")
+	}
+
+	file, err := os.Open(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	subscriptions, err := parseOPML(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read %s: %w", cmd.arguments[0], err)
+	}
+
+	following, err := s.db.GetFeedFollowForUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	followed := make(map[uuid.UUID]bool, len(following))
+	for _, follow := range following {
+		followed[follow.FeedID] = true
+	}
+
+	// Feed names are unique, and exports often reuse titles like "News".
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	takenNames := make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		takenNames[feed.Name] = true
+	}
+
+	created, followedCount, skipped, failed := 0, 0, 0, 0
+	for _, sub := range subscriptions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		feed, err := s.db.GetFeedByUrl(ctx, sub.URL)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			name := uniqueFeedName(sub.Name, sub.URL, takenNames)
+			fmt.Printf("Adding %s (%s)\n", name, sub.URL)
+			feed, _, err = addFeed(ctx, s, user, name, sub.URL, addFeedOptions{
+				choose:  firstFeedCandidate,
+				folder:  sub.Folder,
+				siteURL: sub.SiteURL,
+			})
+			if err != nil {
+				fmt.Printf("Failed to add %s: %v\n", sub.URL, err)
+				failed++
+				continue
+			}
+			takenNames[feed.Name] = true
+			followed[feed.ID] = true
+			created++
+		case err != nil:
+			return err
+		case followed[feed.ID]:
+			skipped++
+		default:
+			_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+				ID:     uuid.New(),
+				UserID: user.ID,
+				FeedID: feed.ID,
+				Folder: sql.NullString{
+					String: sub.Folder,
+					Valid:  sub.Folder != "",
+				},
+			})
+			if err != nil {
+				fmt.Printf("Failed to follow %s: %v\n", sub.URL, err)
+				failed++
+				continue
+			}
+			followed[feed.ID] = true
+			followedCount++
+		}
+	}
+
+	fmt.Printf("Imported %d feeds: %d created, %d followed, %d already followed, %d failed\n",
+		len(subscriptions), created, followedCount, skipped, failed)
+
+	return nil
+}
+
+// firstFeedCandidate picks the first advertised feed, for imports that cannot
+// stop to ask.
+func firstFeedCandidate(candidates []feedCandidate) (feedCandidate, error) {
+	return candidates[0], nil
+}
+
+// uniqueFeedName returns name if no feed uses it yet. Otherwise it adds the
+// feed's host, and then a number, until the name is free.
+func uniqueFeedName(name, feedURL string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		name = fmt.Sprintf("%s (%s)", name, u.Host)
+		if !taken[name] {
+			return name
+		}
+	}
+
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s %d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerRead marks a single post, every post of a feed, or every post the
+// user follows as read.
+func handlerRead(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("usage: read <post-id|all|feed-url>")
+	}
+	target := cmd.arguments[0]
+
+	if target == "all" {
+		marked, err := s.db.MarkAllPostsRead(ctx, database.MarkAllPostsReadParams{
+			ReadAt: time.Now(),
+			UserID: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked %d posts as read\n", marked)
+		return nil
+	}
+
+	if postID, err := uuid.Parse(target); err == nil {
+		marked, err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now(),
+			PostID: postID,
+		})
+		if err != nil {
+			return err
+		}
+		if marked == 0 {
+			return fmt.Errorf("no post with id %s", postID)
+		}
+		fmt.Printf("Marked %s as read\n", postID)
+		return nil
+	}
+
+	feed, err := s.db.GetFeedByUrl(ctx, target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s is neither a post id nor the url of a feed", target)
+	}
+	if err != nil {
+		return err
+	}
+
+	marked, err := s.db.MarkFeedPostsRead(ctx, database.MarkFeedPostsReadParams{
+		UserID: user.ID,
+		ReadAt: time.Now(),
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Marked %d posts from %s as read\n", marked, feed.Name)
+
+	return nil
+}
+
+func handlerUnread(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("usage: unread <post-id>")
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+
+	unmarked, err := s.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return err
+	}
+	if unmarked == 0 {
+		fmt.Printf("%s was not marked as read\n", postID)
+		return nil
+	}
+	fmt.Printf("Marked %s as unread\n", postID)
+
+	return nil
+}
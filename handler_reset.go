@@ -2,23 +2,28 @@ package main
 
 import "context"
 
-func handlerReset(s *state, cmd command) error {
-	err := s.db.DeleteUsers(context.Background())
+func handlerReset(ctx context.Context, s *state, cmd command) error {
+	err := s.db.DeleteUsers(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.db.DeleteFeeds(context.Background())
+	err = s.db.DeleteFeeds(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.db.DeleteFeedFollows(context.Background())
+	err = s.db.DeleteFeedFollows(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.db.DeletePosts(context.Background())
+	err = s.db.DeletePosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteFeedFetches(ctx)
 	if err != nil {
 		return err
 	}
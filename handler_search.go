@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/RafaelTauschek/internal/markup"
+)
+
+// handlerSearch runs a web-style query ("quoted phrases", or, -exclusions)
+// over the posts of the feeds the user follows, best matches first.
+func handlerSearch(ctx context.Context, s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	limit := fs.Int("limit", 10, "maximum number of results")
+
+	arguments, err := parseFlags(fs, cmd.arguments)
+	if err != nil {
+		return err
+	}
+	query := strings.TrimSpace(strings.Join(arguments, " "))
+	if query == "" {
+		return errors.New("usage: search <query> [--limit n]")
+	}
+
+	results, err := s.db.SearchPostsForUser(ctx, database.SearchPostsForUserParams{
+		Query:      query,
+		UserID:     user.ID,
+		MaxResults: int32(*limit),
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 && s.output == outputText {
+		fmt.Printf("No posts match %q\n", query)
+		return nil
+	}
+
+	t := newTable("id", "title", "url", "feed_name", "published_at", "rank", "snippet")
+	for _, result := range results {
+		t.add(result.ID, result.Title, result.Url, result.FeedName, result.PublishedAt, result.Rank,
+			markup.Plain(result.Snippet))
+	}
+
+	return renderTable(s, t, func(i int) {
+		result := results[i]
+		fmt.Println("***********************")
+		fmt.Printf("ID: %s\n", result.ID)
+		fmt.Printf("Title: %s\n", result.Title)
+		fmt.Printf("Match: %s\n", markup.Plain(result.Snippet))
+		fmt.Printf("Link: %s\n", result.Url)
+		fmt.Printf("Feed: %s\n", result.FeedName)
+		fmt.Printf("From: %s\n", result.PublishedAt.Time)
+		fmt.Println("***********************")
+	})
+}
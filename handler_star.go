@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RafaelTauschek/internal/database"
+	"github.com/RafaelTauschek/internal/markup"
+	"github.com/google/uuid"
+)
+
+func handlerStar(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("usage: star <post-id>")
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+
+	starred, err := s.db.StarPost(ctx, database.StarPostParams{
+		UserID:    user.ID,
+		StarredAt: time.Now(),
+		PostID:    postID,
+	})
+	if err != nil {
+		return err
+	}
+	if starred == 0 {
+		return fmt.Errorf("no post with id %s", postID)
+	}
+	fmt.Printf("Starred %s\n", postID)
+
+	return nil
+}
+
+func handlerUnstar(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) != 1 {
+		return errors.New("usage: unstar <post-id>")
+	}
+
+	postID, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return err
+	}
+
+	unstarred, err := s.db.UnstarPost(ctx, database.UnstarPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return err
+	}
+	if unstarred == 0 {
+		fmt.Printf("%s was not starred\n", postID)
+		return nil
+	}
+	fmt.Printf("Unstarred %s\n", postID)
+
+	return nil
+}
+
+// handlerStarred lists starred posts, most recently starred first. Unlike
+// browse it includes posts from feeds the user no longer follows.
+func handlerStarred(ctx context.Context, s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) > 1 {
+		return errors.New("too many arguments provided")
+	}
+
+	limit := 20
+	if len(cmd.arguments) == 1 {
+		cmdLimit, err := strconv.Atoi(cmd.arguments[0])
+		if err != nil {
+			return err
+		}
+		limit = cmdLimit
+	}
+
+	posts, err := s.db.GetStarredPostsForUser(ctx, database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	t := newTable("id", "title", "url", "feed_name", "published_at", "starred_at", "summary")
+	for _, post := range posts {
+		t.add(post.ID, post.Title, post.Url, post.FeedName, post.PublishedAt, post.StarredAt,
+			summarize(markup.Plain(postSummary(post.Description, post.Content)), summaryLength))
+	}
+
+	return renderTable(s, t, func(i int) {
+		post := posts[i]
+		fmt.Println("***********************")
+		fmt.Printf("ID: %s\n", post.ID)
+		fmt.Printf("Title: %s\n", post.Title)
+		fmt.Printf("Description: %s\n", summarize(markup.Plain(postSummary(post.Description, post.Content)), summaryLength))
+		fmt.Printf("Link: %s\n", post.Url)
+		fmt.Printf("Feed: %s\n", post.FeedName)
+		fmt.Printf("From: %s\n", post.PublishedAt.Time)
+		fmt.Printf("Starred: %s\n", post.StarredAt)
+		fmt.Println("***********************")
+	})
+}
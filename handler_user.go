@@ -10,14 +10,14 @@ import (
 	"github.com/google/uuid"
 )
 
-func handlerRegister(s *state, cmd command) error {
+func handlerRegister(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.arguments) != 1 {
 		return errors.New("no arguments provided")
 	}
 
 	name := cmd.arguments[0]
 
-	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
+	user, err := s.db.CreateUser(ctx, database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -36,32 +36,35 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerUsers(s *state, cmd command) error {
-	users, err := s.db.GetUsers(context.Background())
+func handlerUsers(ctx context.Context, s *state, cmd command) error {
+	users, err := s.db.GetUsers(ctx)
 
 	if err != nil {
 		return err
 	}
 
+	t := newTable("name", "current")
 	for _, user := range users {
+		t.add(user.Name, user.Name == s.cfg.CurrentUser)
+	}
 
+	return renderTable(s, t, func(i int) {
+		user := users[i]
 		if user.Name == s.cfg.CurrentUser {
 			fmt.Printf("* %s (current)\n", user.Name)
 		} else {
 			fmt.Printf("* %s\n", user.Name)
 		}
-	}
-
-	return nil
+	})
 }
 
-func handlerLogin(s *state, cmd command) error {
+func handlerLogin(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.arguments) != 1 {
 		return errors.New("no argument provided")
 	}
 	username := cmd.arguments[0]
 
-	_, err := s.db.GetUser(context.Background(), username)
+	_, err := s.db.GetUser(ctx, username)
 	if err != nil {
 		return err
 	}
@@ -13,6 +13,74 @@ import (
 	"github.com/google/uuid"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET locked_by = $1, locked_until = now() + $2::float8 * interval '1 second'
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE (feeds.locked_until IS NULL OR feeds.locked_until < now())
+    AND (feeds.next_fetch_at IS NULL OR feeds.next_fetch_at <= now())
+    ORDER BY last_fechted_at ASC NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language
+`
+
+type ClaimFeedsToFetchParams struct {
+	LockedBy     sql.NullString
+	LeaseSeconds float64
+	MaxFeeds     int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch,
+		arg.LockedBy,
+		arg.LeaseSeconds,
+		arg.MaxFeeds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFechtedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LockedBy,
+			&i.LockedUntil,
+			&i.LastFetchStatus,
+			&i.LastFetchError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.SiteLink,
+			&i.ChannelTitle,
+			&i.Description,
+			&i.IconUrl,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -23,7 +91,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language
 `
 
 type CreateFeedParams struct {
@@ -53,6 +121,19 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFechtedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.LastFetchStatus,
+		&i.LastFetchError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.SiteLink,
+		&i.ChannelTitle,
+		&i.Description,
+		&i.IconUrl,
+		&i.Language,
 	)
 	return i, err
 }
@@ -67,7 +148,7 @@ func (q *Queries) DeleteFeeds(ctx context.Context) error {
 }
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fechted_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
@@ -81,12 +162,25 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFechtedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.LastFetchStatus,
+		&i.LastFetchError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.SiteLink,
+		&i.ChannelTitle,
+		&i.Description,
+		&i.IconUrl,
+		&i.Language,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fechted_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -106,6 +200,19 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFechtedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.LockedBy,
+			&i.LockedUntil,
+			&i.LastFetchStatus,
+			&i.LastFetchError,
+			&i.ConsecutiveFailures,
+			&i.NextFetchAt,
+			&i.SiteLink,
+			&i.ChannelTitle,
+			&i.Description,
+			&i.IconUrl,
+			&i.Language,
 		); err != nil {
 			return nil, err
 		}
@@ -120,14 +227,37 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fechted_at FROM feeds
-ORDER BY last_fechted_at ASC NULLS FIRST
-LIMIT 1
+const markFeedFetchFailed = `-- name: MarkFeedFetchFailed :one
+UPDATE feeds
+SET last_fechted_at = $1, updated_at = $2,
+    last_fetch_status = $3, last_fetch_error = $4,
+    consecutive_failures = consecutive_failures + 1,
+    next_fetch_at = now() + $5::float8 * interval '1 second',
+    locked_by = NULL, locked_until = NULL
+WHERE id = $6 AND locked_by = $7
+RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+type MarkFeedFetchFailedParams struct {
+	LastFechtedAt   sql.NullTime
+	UpdatedAt       time.Time
+	LastFetchStatus sql.NullInt32
+	LastFetchError  sql.NullString
+	BackoffSeconds  float64
+	ID              uuid.UUID
+	LockedBy        sql.NullString
+}
+
+func (q *Queries) MarkFeedFetchFailed(ctx context.Context, arg MarkFeedFetchFailedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, markFeedFetchFailed,
+		arg.LastFechtedAt,
+		arg.UpdatedAt,
+		arg.LastFetchStatus,
+		arg.LastFetchError,
+		arg.BackoffSeconds,
+		arg.ID,
+		arg.LockedBy,
+	)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -137,25 +267,52 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFechtedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.LastFetchStatus,
+		&i.LastFetchError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.SiteLink,
+		&i.ChannelTitle,
+		&i.Description,
+		&i.IconUrl,
+		&i.Language,
 	)
 	return i, err
 }
 
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
-SET last_fechted_at = $1, updated_at = $2
-WHERE id = $3
-RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at
+SET last_fechted_at = $1, updated_at = $2, etag = $3, last_modified = $4,
+    last_fetch_status = $5, last_fetch_error = NULL, consecutive_failures = 0, next_fetch_at = NULL,
+    locked_by = NULL, locked_until = NULL
+WHERE id = $6 AND locked_by = $7
+RETURNING id, created_at, updated_at, name, url, user_id, last_fechted_at, etag, last_modified, locked_by, locked_until, last_fetch_status, last_fetch_error, consecutive_failures, next_fetch_at, site_link, channel_title, description, icon_url, language
 `
 
 type MarkFeedFetchedParams struct {
-	LastFechtedAt sql.NullTime
-	UpdatedAt     time.Time
-	ID            uuid.UUID
+	LastFechtedAt   sql.NullTime
+	UpdatedAt       time.Time
+	Etag            sql.NullString
+	LastModified    sql.NullString
+	LastFetchStatus sql.NullInt32
+	ID              uuid.UUID
+	LockedBy        sql.NullString
 }
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, markFeedFetched, arg.LastFechtedAt, arg.UpdatedAt, arg.ID)
+	row := q.db.QueryRowContext(ctx, markFeedFetched,
+		arg.LastFechtedAt,
+		arg.UpdatedAt,
+		arg.Etag,
+		arg.LastModified,
+		arg.LastFetchStatus,
+		arg.ID,
+		arg.LockedBy,
+	)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -165,6 +322,76 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 		&i.Url,
 		&i.UserID,
 		&i.LastFechtedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.LockedBy,
+		&i.LockedUntil,
+		&i.LastFetchStatus,
+		&i.LastFetchError,
+		&i.ConsecutiveFailures,
+		&i.NextFetchAt,
+		&i.SiteLink,
+		&i.ChannelTitle,
+		&i.Description,
+		&i.IconUrl,
+		&i.Language,
 	)
 	return i, err
 }
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET locked_by = NULL, locked_until = NULL
+WHERE id = $1 AND locked_by = $2
+`
+
+type ReleaseFeedLeaseParams struct {
+	ID       uuid.UUID
+	LockedBy sql.NullString
+}
+
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.ID, arg.LockedBy)
+	return err
+}
+
+const setFeedSiteLinkIfMissing = `-- name: SetFeedSiteLinkIfMissing :exec
+UPDATE feeds SET site_link = $1 WHERE id = $2 AND site_link IS NULL
+`
+
+type SetFeedSiteLinkIfMissingParams struct {
+	SiteLink sql.NullString
+	ID       uuid.UUID
+}
+
+func (q *Queries) SetFeedSiteLinkIfMissing(ctx context.Context, arg SetFeedSiteLinkIfMissingParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteLinkIfMissing, arg.SiteLink, arg.ID)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET site_link = $1, channel_title = $2, description = $3, icon_url = $4, language = $5
+WHERE id = $6
+`
+
+type UpdateFeedMetadataParams struct {
+	SiteLink     sql.NullString
+	ChannelTitle sql.NullString
+	Description  sql.NullString
+	IconUrl      sql.NullString
+	Language     sql.NullString
+	ID           uuid.UUID
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.SiteLink,
+		arg.ChannelTitle,
+		arg.Description,
+		arg.IconUrl,
+		arg.Language,
+		arg.ID,
+	)
+	return err
+}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,11 +15,11 @@ import (
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows(id, created_at, updated_at, user_id, feed_id)
-    VALUES ($1, NOW(), NOW(), $2, $3)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follows(id, created_at, updated_at, user_id, feed_id, folder)
+    VALUES ($1, NOW(), NOW(), $2, $3, $4)
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder
 )
-SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, users.name AS user_name, feeds.name AS feed_name
+SELECT inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder, users.name AS user_name, feeds.name AS feed_name
 FROM inserted_feed_follow
 INNER JOIN users ON inserted_feed_follow.user_id = users.id
 INNER JOIN feeds ON inserted_feed_follow.feed_id = feeds.id
@@ -28,6 +29,7 @@ type CreateFeedFollowParams struct {
 	ID     uuid.UUID
 	UserID uuid.UUID
 	FeedID uuid.UUID
+	Folder sql.NullString
 }
 
 type CreateFeedFollowRow struct {
@@ -36,12 +38,18 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Folder    sql.NullString
 	UserName  string
 	FeedName  string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
-	row := q.db.QueryRowContext(ctx, createFeedFollow, arg.ID, arg.UserID, arg.FeedID)
+	row := q.db.QueryRowContext(ctx, createFeedFollow,
+		arg.ID,
+		arg.UserID,
+		arg.FeedID,
+		arg.Folder,
+	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
 		&i.ID,
@@ -49,6 +57,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.Folder,
 		&i.UserName,
 		&i.FeedName,
 	)
@@ -65,7 +74,7 @@ func (q *Queries) DeleteFeedFollows(ctx context.Context) error {
 }
 
 const getFeedFollowForUser = `-- name: GetFeedFollowForUser :many
-SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, users.name AS user_name, feeds.name AS feeds_name
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder, users.name AS user_name, feeds.name AS feeds_name, feeds.url AS feed_url, feeds.site_link AS feed_site_link
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds on feed_follows.feed_id = feeds.id
@@ -73,13 +82,16 @@ WHERE feed_follows.user_id = $1
 `
 
 type GetFeedFollowForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	UserName  string
-	FeedsName string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	FeedID       uuid.UUID
+	Folder       sql.NullString
+	UserName     string
+	FeedsName    string
+	FeedUrl      string
+	FeedSiteLink sql.NullString
 }
 
 func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowForUserRow, error) {
@@ -97,8 +109,11 @@ func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.Folder,
 			&i.UserName,
 			&i.FeedsName,
+			&i.FeedUrl,
+			&i.FeedSiteLink,
 		); err != nil {
 			return nil, err
 		}
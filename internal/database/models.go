@@ -11,14 +11,50 @@ import (
 	"github.com/google/uuid"
 )
 
+type Enclosure struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	PostID          uuid.UUID
+	Url             string
+	MimeType        sql.NullString
+	Length          sql.NullInt64
+	DurationSeconds sql.NullInt32
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFechtedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFechtedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	LockedBy            sql.NullString
+	LockedUntil         sql.NullTime
+	LastFetchStatus     sql.NullInt32
+	LastFetchError      sql.NullString
+	ConsecutiveFailures int32
+	NextFetchAt         sql.NullTime
+	SiteLink            sql.NullString
+	ChannelTitle        sql.NullString
+	Description         sql.NullString
+	IconUrl             sql.NullString
+	Language            sql.NullString
+}
+
+type FeedFetch struct {
+	ID           uuid.UUID
+	FeedID       uuid.UUID
+	FetchedAt    time.Time
+	DurationMs   int32
+	StatusCode   sql.NullInt32
+	Bytes        int64
+	NewPosts     int32
+	Error        sql.NullString
+	UpdatedPosts int32
 }
 
 type FeedFollow struct {
@@ -27,17 +63,35 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Folder    sql.NullString
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	PublishedAtInferred bool
+	Guid                string
+	ContentHash         sql.NullString
+	Content             sql.NullString
+	SearchVector        interface{}
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	StarredAt time.Time
 }
 
 type User struct {
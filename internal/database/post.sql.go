@@ -13,46 +13,127 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const adoptLegacyPost = `-- name: AdoptLegacyPost :exec
+UPDATE posts
+SET guid = $1
+WHERE posts.feed_id = $2 AND posts.url = $3 AND posts.guid = posts.url
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = $2 AND existing.guid = $1
+)
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+type AdoptLegacyPostParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
+// Posts stored before guids were tracked were keyed on their url. Give such a
+// post the item's real guid, so that the upsert which follows updates it
+// instead of inserting a duplicate.
+func (q *Queries) AdoptLegacyPost(ctx context.Context, arg AdoptLegacyPostParams) error {
+	_, err := q.db.ExecContext(ctx, adoptLegacyPost, arg.Guid, arg.FeedID, arg.Url)
+	return err
+}
+
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_inferred, posts.guid, posts.content_hash, posts.content, feeds.name AS feed_name, post_reads.read_at, post_stars.starred_at FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON posts.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+LEFT JOIN post_stars ON post_stars.post_id = posts.id AND post_stars.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND ($2::boolean OR post_reads.post_id IS NULL)
+AND (NOT $3::boolean OR post_stars.post_id IS NOT NULL)
+AND ($4::uuid IS NULL OR posts.feed_id = $4)
+AND ($5::timestamp IS NULL OR posts.published_at >= $5)
+AND ($6::timestamp IS NULL OR posts.published_at < $6)
+ORDER BY
+    CASE WHEN $7::text = 'feed' THEN feeds.name END ASC,
+    CASE WHEN $7::text = 'fetched' THEN posts.created_at END DESC,
+    posts.published_at DESC NULLS LAST,
+    posts.id
+LIMIT $8 OFFSET $9
+`
+
+type BrowsePostsForUserParams struct {
+	UserID      uuid.UUID
+	IncludeRead bool
+	StarredOnly bool
+	FeedID      uuid.NullUUID
+	Since       sql.NullTime
+	Until       sql.NullTime
+	SortBy      string
+	MaxPosts    int32
+	SkipPosts   int32
+}
+
+type BrowsePostsForUserRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	PublishedAtInferred bool
+	Guid                string
+	ContentHash         sql.NullString
+	Content             sql.NullString
+	FeedName            string
+	ReadAt              sql.NullTime
+	StarredAt           sql.NullTime
+}
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.UserID,
+		arg.IncludeRead,
+		arg.StarredOnly,
 		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.SortBy,
+		arg.MaxPosts,
+		arg.SkipPosts,
 	)
-	var i Post
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Title,
-		&i.Url,
-		&i.Description,
-		&i.PublishedAt,
-		&i.FeedID,
-	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.PublishedAtInferred,
+			&i.Guid,
+			&i.ContentHash,
+			&i.Content,
+			&i.FeedName,
+			&i.ReadAt,
+			&i.StarredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const deletePosts = `-- name: DeletePosts :exec
@@ -64,51 +145,54 @@ func (q *Queries) DeletePosts(ctx context.Context) error {
 	return err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, feeds.name AS feed_name FROM posts
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query)::float8 AS rank,
+    ts_headline('english', coalesce(posts.content, posts.description, posts.title), query,
+        'StartSel=**, StopSel=**, MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 JOIN feeds ON posts.feed_id = feeds.id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+CROSS JOIN websearch_to_tsquery('english', $1) AS query
+WHERE feed_follows.user_id = $2
+AND posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT $3
 `
 
-type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+type SearchPostsForUserParams struct {
+	Query      string
+	UserID     uuid.UUID
+	MaxResults int32
 }
 
-type GetPostsForUserRow struct {
+type SearchPostsForUserRow struct {
 	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
 	Title       string
 	Url         string
-	Description sql.NullString
 	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
 	FeedName    string
+	Rank        float64
+	Snippet     string
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.Query, arg.UserID, arg.MaxResults)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetPostsForUserRow
+	var items []SearchPostsForUserRow
 	for rows.Next() {
-		var i GetPostsForUserRow
+		var i SearchPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
 			&i.Title,
 			&i.Url,
-			&i.Description,
 			&i.PublishedAt,
-			&i.FeedID,
 			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -122,3 +206,84 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_inferred, guid, content_hash, content)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    published_at = CASE WHEN EXCLUDED.published_at_inferred THEN posts.published_at ELSE EXCLUDED.published_at END,
+    published_at_inferred = posts.published_at_inferred AND EXCLUDED.published_at_inferred,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = EXCLUDED.updated_at
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_inferred, guid, content_hash, content, (xmax = 0)::boolean AS inserted
+`
+
+type UpsertPostParams struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	PublishedAtInferred bool
+	Guid                string
+	ContentHash         sql.NullString
+	Content             sql.NullString
+}
+
+type UpsertPostRow struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Title               string
+	Url                 string
+	Description         sql.NullString
+	PublishedAt         sql.NullTime
+	FeedID              uuid.UUID
+	PublishedAtInferred bool
+	Guid                string
+	ContentHash         sql.NullString
+	Content             sql.NullString
+	Inserted            bool
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.PublishedAtInferred,
+		arg.Guid,
+		arg.ContentHash,
+		arg.Content,
+	)
+	var i UpsertPostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.PublishedAtInferred,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Content,
+		&i.Inserted,
+	)
+	return i, err
+}
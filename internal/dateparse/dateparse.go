@@ -0,0 +1,211 @@
+// Package dateparse reads the publication dates found in real-world feeds,
+// which rarely stick to the layout their format prescribes.
+package dateparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrEmpty = errors.New("empty date")
+
+// layouts are tried in order after the input has been normalized: weekday
+// names are removed and named zones are rewritten as numeric offsets.
+var layouts = []string{
+	// RFC 822 / 1123 and their common deviations.
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2-Jan-2006 15:04:05 -0700",
+	"2-Jan-06 15:04:05 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05",
+	"2 Jan 2006",
+	"2 January 2006",
+
+	// ANSI C and Unix date output.
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 -0700 2006",
+
+	// ISO 8601 / RFC 3339 and their relatives.
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+
+	// Prose dates.
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006 15:04 -0700",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04 -0700",
+	"Jan 2, 2006",
+}
+
+// zones maps the abbreviations seen in feeds to their UTC offset in minutes.
+// Ambiguous abbreviations resolve to the most common meaning in feeds.
+var zones = map[string]int{
+	"UT":   0,
+	"UTC":  0,
+	"GMT":  0,
+	"Z":    0,
+	"WET":  0,
+	"WEST": 60,
+	"BST":  60,
+	"CET":  60,
+	"CEST": 120,
+	"MET":  60,
+	"MEST": 120,
+	"EET":  120,
+	"EEST": 180,
+	"MSK":  180,
+	"IST":  330,
+	"SGT":  480,
+	"HKT":  480,
+	"AWST": 480,
+	"CST":  -360,
+	"JST":  540,
+	"KST":  540,
+	"ACST": 570,
+	"ACDT": 630,
+	"AEST": 600,
+	"AEDT": 660,
+	"NZST": 720,
+	"NZDT": 780,
+	"HST":  -600,
+	"AKST": -540,
+	"AKDT": -480,
+	"PST":  -480,
+	"PDT":  -420,
+	"MST":  -420,
+	"MDT":  -360,
+	"CDT":  -300,
+	"EST":  -300,
+	"EDT":  -240,
+	"AST":  -240,
+	"ADT":  -180,
+	"NST":  -210,
+	"NDT":  -150,
+}
+
+var weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+var (
+	commentPattern = regexp.MustCompile(`\s*\([^)]*\)`)
+	spacePattern   = regexp.MustCompile(`\s+`)
+	offsetPattern  = regexp.MustCompile(`^([+-])(\d{1,2}):?(\d{2})?$`)
+	letterPattern  = regexp.MustCompile(`^[A-Za-z]+$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+)
+
+// Parse interprets s as a feed date and returns it in UTC. Dates without a
+// zone are taken to be UTC.
+func Parse(s string) (time.Time, error) {
+	value := normalize(s)
+	if value == "" {
+		return time.Time{}, ErrEmpty
+	}
+
+	if t, ok := parseLayouts(value); ok {
+		return t, nil
+	}
+
+	// An unknown zone name is better read as UTC than not at all.
+	if i := strings.LastIndex(value, " "); i > 0 && letterPattern.MatchString(value[i+1:]) {
+		if t, ok := parseLayouts(value[:i]); ok {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+func parseLayouts(value string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func normalize(s string) string {
+	s = commentPattern.ReplaceAllString(s, "")
+	s = strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+	if s == "" {
+		return s
+	}
+
+	fields := strings.Split(s, " ")
+
+	first := strings.ToLower(strings.TrimSuffix(fields[0], ","))
+	for _, day := range weekdays {
+		if strings.HasPrefix(first, day) {
+			fields = fields[1:]
+			break
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	last := len(fields) - 1
+	fields[last] = normalizeZone(fields[last])
+	// Unix date output puts the zone before the year.
+	if last > 0 && yearPattern.MatchString(fields[last]) {
+		fields[last-1] = normalizeZone(fields[last-1])
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// normalizeZone rewrites a trailing zone token as a -0700 style offset so a
+// single layout covers named zones, "+05:30" and "+0530" alike.
+func normalizeZone(zone string) string {
+	upper := strings.ToUpper(zone)
+	if offset, ok := zones[upper]; ok {
+		return formatOffset(offset)
+	}
+
+	for _, prefix := range []string{"GMT", "UTC"} {
+		if rest, ok := strings.CutPrefix(upper, prefix); ok && rest != "" {
+			upper = rest
+			break
+		}
+	}
+
+	match := offsetPattern.FindStringSubmatch(upper)
+	if match == nil {
+		return zone
+	}
+	minutes := match[3]
+	if minutes == "" {
+		minutes = "00"
+	}
+	return fmt.Sprintf("%s%02s%s", match[1], match[2], minutes)
+}
+
+func formatOffset(minutes int) string {
+	sign := "+"
+	if minutes < 0 {
+		sign = "-"
+		minutes = -minutes
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, minutes/60, minutes%60)
+}
@@ -0,0 +1,120 @@
+package dateparse
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  time.Time
+	}{
+		// RFC 822 / 1123 and their deviations.
+		{"rfc1123z", "Mon, 02 Jan 2006 15:04:05 -0700", utc(2006, 1, 2, 22, 4, 5)},
+		{"rfc1123 gmt", "Tue, 10 Jun 2003 04:00:00 GMT", utc(2003, 6, 10, 4, 0, 0)},
+		{"no seconds", "02 Jan 2006 15:04 +0000", utc(2006, 1, 2, 15, 4, 0)},
+		{"long month", "2 January 2006 15:04:05 +0100", utc(2006, 1, 2, 14, 4, 5)},
+		{"dashed", "2-Jan-2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+		{"no zone", "2 Jan 2006 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"date only", "2 Jan 2006", utc(2006, 1, 2, 0, 0, 0)},
+		{"extra whitespace", "  Mon,  2 Jan  2006   15:04:05  GMT ", utc(2006, 1, 2, 15, 4, 5)},
+		{"comment", "Mon, 2 Jan 2006 15:04:05 +0000 (UTC)", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Two-digit years.
+		{"two-digit year", "Mon, 02 Jan 06 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+		{"two-digit year last century", "Fri, 31 Dec 99 23:00 GMT", utc(1999, 12, 31, 23, 0, 0)},
+		{"two-digit dashed", "2-Jan-06 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+
+		// ANSI C and Unix date output.
+		{"ansic", "Mon Jan 2 15:04:05 2006", utc(2006, 1, 2, 15, 4, 5)},
+		{"unix date", "Mon Jan 2 15:04:05 PST 2006", utc(2006, 1, 2, 23, 4, 5)},
+
+		// ISO 8601 / RFC 3339.
+		{"rfc3339", "2006-01-02T15:04:05Z", utc(2006, 1, 2, 15, 4, 5)},
+		{"rfc3339 offset", "2006-01-02T15:04:05+02:00", utc(2006, 1, 2, 13, 4, 5)},
+		{"rfc3339 fraction", "2006-01-02T15:04:05.123Z", time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC)},
+		{"iso compact offset", "2006-01-02T15:04:05-0500", utc(2006, 1, 2, 20, 4, 5)},
+		{"iso no seconds", "2006-01-02T15:04Z", utc(2006, 1, 2, 15, 4, 0)},
+		{"iso local", "2006-01-02T15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"iso space", "2006-01-02 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+		{"iso space offset", "2006-01-02 15:04:05 +0100", utc(2006, 1, 2, 14, 4, 5)},
+		{"iso date", "2006-01-02", utc(2006, 1, 2, 0, 0, 0)},
+		{"slashes", "2006/01/02 15:04:05", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Prose dates.
+		{"prose", "January 2, 2006", utc(2006, 1, 2, 0, 0, 0)},
+		{"prose short", "Jan 2, 2006 15:04 EST", utc(2006, 1, 2, 20, 4, 0)},
+		{"prose weekday", "Monday, January 2, 2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Zone abbreviations.
+		{"ut", "2 Jan 2006 12:00:00 UT", utc(2006, 1, 2, 12, 0, 0)},
+		{"z", "2 Jan 2006 12:00:00 Z", utc(2006, 1, 2, 12, 0, 0)},
+		{"est", "2 Jan 2006 12:00:00 EST", utc(2006, 1, 2, 17, 0, 0)},
+		{"pdt", "2 Jan 2006 12:00:00 PDT", utc(2006, 1, 2, 19, 0, 0)},
+		{"cest", "2 Jan 2006 12:00:00 CEST", utc(2006, 1, 2, 10, 0, 0)},
+		{"ist", "2 Jan 2006 12:00:00 IST", utc(2006, 1, 2, 6, 30, 0)},
+		{"aedt", "2 Jan 2006 12:00:00 AEDT", utc(2006, 1, 2, 1, 0, 0)},
+		{"lowercase zone", "2 Jan 2006 12:00:00 est", utc(2006, 1, 2, 17, 0, 0)},
+
+		// Offsets written after GMT or UTC, or with a colon.
+		{"gmt+2", "2 Jan 2006 12:00:00 GMT+2", utc(2006, 1, 2, 10, 0, 0)},
+		{"gmt-08:00", "2 Jan 2006 12:00:00 GMT-08:00", utc(2006, 1, 2, 20, 0, 0)},
+		{"utc+05:30", "2 Jan 2006 12:00:00 UTC+05:30", utc(2006, 1, 2, 6, 30, 0)},
+		{"colon offset", "2 Jan 2006 12:00:00 +05:30", utc(2006, 1, 2, 6, 30, 0)},
+
+		// Weekdays are ignored, even when wrong or spelled out.
+		{"wrong weekday", "Fri, 02 Jan 2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+		{"long weekday", "Monday, 02 Jan 2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+		{"weekday no comma", "Mon 02 Jan 2006 15:04:05 +0000", utc(2006, 1, 2, 15, 4, 5)},
+
+		// Unknown zone names fall back to UTC.
+		{"unknown zone", "2 Jan 2006 12:00:00 XYZT", utc(2006, 1, 2, 12, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("Parse(%q) returned location %v, want UTC", tt.input, got.Location())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"garbage", "not a date"},
+		{"month out of range", "2006-13-02"},
+		{"weekday only", "Monday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Parse(tt.input); err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.input, got)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "   ", "(no date)"} {
+		if _, err := Parse(input); !errors.Is(err, ErrEmpty) {
+			t.Errorf("Parse(%q) error = %v, want ErrEmpty", input, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package markup
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// allowedTags lists the elements kept by Sanitize and the attributes each
+// may carry. Anything else is unwrapped: the tag goes, its text stays.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"li":         nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          nil,
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         nil,
+	"th":         nil,
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[string]bool{
+	"applet":   true,
+	"embed":    true,
+	"frame":    true,
+	"frameset": true,
+	"head":     true,
+	"iframe":   true,
+	"math":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+var voidTags = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+}
+
+// Sanitize reduces an HTML fragment to a safe subset for storage: scripts,
+// frames, event handlers, styles, unsafe URLs and tracking pixels are
+// removed and the remaining markup is re-serialized with balanced tags.
+func Sanitize(s string) string {
+	var b strings.Builder
+	var open []string
+	dropping := ""
+	dropDepth := 0
+
+	for _, token := range Tokenize(s) {
+		if dropping != "" {
+			switch {
+			case token.Type == StartTagToken && token.Data == dropping:
+				dropDepth++
+			case token.Type == EndTagToken && token.Data == dropping:
+				dropDepth--
+				if dropDepth == 0 {
+					dropping = ""
+				}
+			}
+			continue
+		}
+
+		switch token.Type {
+		case TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+
+		case StartTagToken, SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if token.Type == StartTagToken {
+					dropping = token.Data
+					dropDepth = 1
+				}
+				continue
+			}
+			attrs, ok := allowedTags[token.Data]
+			if !ok || isTrackingPixel(token) {
+				continue
+			}
+			if token.Data == "a" && !safeURL(token.AttrValue("href")) {
+				continue
+			}
+			if token.Data == "img" && (token.AttrValue("src") == "" || !safeURL(token.AttrValue("src"))) {
+				continue
+			}
+
+			b.WriteString("<" + token.Data)
+			for _, key := range attrs {
+				value := token.AttrValue(key)
+				if value == "" || (urlAttributes[key] && !safeURL(value)) {
+					continue
+				}
+				b.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+			}
+			b.WriteString(">")
+
+			// A self-closing tag such as XHTML's <a/> is an empty element,
+			// so anything but a void tag is closed on the spot.
+			switch {
+			case voidTags[token.Data]:
+			case token.Type == SelfClosingTagToken:
+				b.WriteString("</" + token.Data + ">")
+			default:
+				open = append(open, token.Data)
+			}
+
+		case EndTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// safeURL accepts relative URLs and the http, https and mailto schemes.
+func safeURL(raw string) bool {
+	value := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+
+	colon := strings.IndexByte(value, ':')
+	if colon < 0 || strings.ContainsAny(value[:colon], "/?#") {
+		return true
+	}
+
+	switch strings.ToLower(value[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// isTrackingPixel spots the 1x1 images newsletters and feed services use to
+// count opens.
+func isTrackingPixel(token Token) bool {
+	if token.Data != "img" {
+		return false
+	}
+	for _, key := range []string{"width", "height"} {
+		value := strings.TrimSuffix(strings.TrimSpace(token.AttrValue(key)), "px")
+		if n, err := strconv.Atoi(value); err == nil && n <= 1 {
+			return true
+		}
+	}
+	return false
+}
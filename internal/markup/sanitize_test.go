@@ -0,0 +1,124 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"keeps allowed markup", `<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{"escapes text", `<p>1 &lt; 2 &amp; 3</p>`, `<p>1 &lt; 2 &amp; 3</p>`},
+		{"unwraps unknown tags", `<section><p>Kept</p></section>`, `<p>Kept</p>`},
+		{"drops script", `<p>a</p><script>alert("x")</script><p>b</p>`, `<p>a</p><p>b</p>`},
+		{"drops script with tags inside", `<script>document.write("<p>x</p>")</script>ok`, `ok`},
+		{"drops unclosed script", `ok<script>alert(1)`, `ok`},
+		{"drops iframe", `<p>a<iframe src="https://evil.example/"><p>fallback</p></iframe>b</p>`, `<p>ab</p>`},
+		{"drops nested iframes", `<iframe><iframe></iframe>inner</iframe>after`, `after`},
+		{"drops style", `<style>p { color: red }</style><p>x</p>`, `<p>x</p>`},
+		{"drops object and embed", `<object data="x"><embed src="y"></object>z`, `z`},
+		{"drops event handlers", `<p onclick="alert(1)" class="x">a</p>`, `<p>a</p>`},
+		{"drops style attribute", `<span style="color:red">a</span>`, `<span>a</span>`},
+		{"keeps safe link", `<a href="https://example.com/?a=1&amp;b=2" title="t">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" title="t">x</a>`},
+		{"keeps relative link", `<a href="/post/1">x</a>`, `<a href="/post/1">x</a>`},
+		{"keeps mailto", `<a href="mailto:me@example.com">x</a>`, `<a href="mailto:me@example.com">x</a>`},
+		{"quotes attribute values", `<a href='https://example.com/"onmouseover="x'>x</a>`, `<a href="https://example.com/&#34;onmouseover=&#34;x">x</a>`},
+
+		// Unbalanced markup is closed or ignored.
+		{"closes unclosed tags", `<p><b>bold`, `<p><b>bold</b></p>`},
+		{"closes inner tags first", `<p><b>bold</p>after`, `<p><b>bold</b></p>after`},
+		{"ignores stray end tags", `</div>text</b>`, `text`},
+		{"ignores end tag of dropped element", `<p>a</iframe>b</p>`, `<p>ab</p>`},
+		{"void tags stay unclosed", `<p>a<br>b<hr></p>`, `<p>a<br>b<hr></p>`},
+		{"self-closing void tags", `a<br/>b<hr />`, `a<br>b<hr>`},
+		{"closes self-closing link", `<a href="http://x"/>text after`, `<a href="http://x"></a>text after`},
+		{"closes self-closing blocks", `<p/>hello<div/>world`, `<p></p>hello<div></div>world`},
+		{"self-closing inside open tag", `<p>a<span/>b</p>`, `<p>a<span></span>b</p>`},
+		{"bare less-than", `a < b`, `a &lt; b`},
+		{"drops comments", `a<!-- <script>x</script> -->b`, `ab`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.input); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeUnsafeURLs(t *testing.T) {
+	hrefs := []string{
+		`javascript:alert(1)`,
+		`JavaScript:alert(1)`,
+		` javascript:alert(1)`,
+		"java\tscript:alert(1)",
+		"java\nscript:alert(1)",
+		"java\x00script:alert(1)",
+		"\x01javascript:alert(1)",
+		"javascript\x7f:alert(1)",
+		`&#106;avascript:alert(1)`,
+		`jav&#x09;ascript:alert(1)`,
+		`vbscript:msgbox(1)`,
+		`data:text/html;base64,PHNjcmlwdD4=`,
+		`file:///etc/passwd`,
+	}
+
+	for _, href := range hrefs {
+		input := `<a href="` + href + `">click</a>`
+		got := Sanitize(input)
+		if got != "click" {
+			t.Errorf("Sanitize(%q) = %q, want the link removed", input, got)
+		}
+
+		input = `<img src="` + href + `" alt="x">`
+		if got := Sanitize(input); got != "" {
+			t.Errorf("Sanitize(%q) = %q, want the image removed", input, got)
+		}
+	}
+}
+
+func TestSanitizeImages(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"keeps image", `<img src="https://example.com/a.png" alt="A" onerror="x">`, `<img src="https://example.com/a.png" alt="A">`},
+		{"drops image without src", `<img alt="A">`, ``},
+		{"drops 1x1 pixel", `<p>a<img src="https://t.example/open.gif" width="1" height="1">b</p>`, `<p>ab</p>`},
+		{"drops pixel with px units", `<img src="https://t.example/o.gif" width="1px" height="1px">`, ``},
+		{"drops zero height", `<img src="https://t.example/o.gif" height="0">`, ``},
+		{"drops self-closing pixel", `<img src="https://t.example/o.gif" width="1" height="1"/>x`, `x`},
+		{"keeps sized image", `<img src="https://example.com/a.png" width="640" height="480">`, `<img src="https://example.com/a.png" width="640" height="480">`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.input); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeIsStable(t *testing.T) {
+	inputs := []string{
+		`<p>a <b>b <i>c</p> d`,
+		`<ul><li>one<li>two</ul>`,
+		`<a href="https://example.com/?q=a&b">x &amp; y</a>`,
+	}
+	for _, input := range inputs {
+		once := Sanitize(input)
+		if twice := Sanitize(once); twice != once {
+			t.Errorf("Sanitize is not stable for %q:\n once %q\ntwice %q", input, once, twice)
+		}
+		if strings.Contains(strings.ToLower(once), "<script") {
+			t.Errorf("Sanitize(%q) = %q still contains a script", input, once)
+		}
+	}
+}
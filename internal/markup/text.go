@@ -0,0 +1,356 @@
+package markup
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var blockTags = map[string]bool{
+	"address":    true,
+	"article":    true,
+	"aside":      true,
+	"blockquote": true,
+	"dd":         true,
+	"div":        true,
+	"dl":         true,
+	"dt":         true,
+	"figcaption": true,
+	"figure":     true,
+	"footer":     true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"header":     true,
+	"hr":         true,
+	"li":         true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"section":    true,
+	"table":      true,
+	"tr":         true,
+	"ul":         true,
+}
+
+type list struct {
+	ordered bool
+	items   int
+}
+
+// textRenderer accumulates inline text into paragraphs and writes each one
+// out, wrapped and prefixed, when a block boundary is reached.
+type textRenderer struct {
+	width int
+	out   strings.Builder
+
+	para      strings.Builder
+	marker    string
+	lastItem  bool
+	continued bool
+
+	lists  []list
+	quotes int
+	pre    int
+
+	links []string
+	hrefs []string
+}
+
+// Text renders an HTML fragment as plain text for the terminal, wrapped to
+// width columns (0 disables wrapping). Paragraphs are separated by blank
+// lines, list items are bulleted or numbered and links become numbered
+// footnotes listed at the end.
+func Text(s string, width int) string {
+	r := &textRenderer{width: width}
+	skipping := ""
+
+	for _, token := range Tokenize(s) {
+		if skipping != "" {
+			if token.Type == EndTagToken && token.Data == skipping {
+				skipping = ""
+			}
+			continue
+		}
+
+		switch token.Type {
+		case TextToken:
+			r.text(token.Data)
+		case StartTagToken, SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if token.Type == StartTagToken {
+					skipping = token.Data
+				}
+				continue
+			}
+			r.start(token)
+		case EndTagToken:
+			r.end(token.Data)
+		}
+	}
+	r.flush()
+
+	for i, link := range r.links {
+		if i == 0 {
+			r.separate()
+		}
+		fmt.Fprintf(&r.out, "[%d] %s\n", i+1, link)
+	}
+
+	return strings.TrimRight(r.out.String(), "\n")
+}
+
+func (r *textRenderer) start(token Token) {
+	if blockTags[token.Data] {
+		r.flush()
+	}
+
+	switch token.Data {
+	case "br":
+		if r.pre > 0 {
+			r.para.WriteString("\n")
+		} else {
+			r.flushLine()
+		}
+	case "hr":
+		r.separate()
+		r.out.WriteString(r.prefix() + "----\n")
+	case "ul", "ol":
+		r.lists = append(r.lists, list{ordered: token.Data == "ol"})
+	case "li":
+		if len(r.lists) == 0 {
+			r.marker = "- "
+			break
+		}
+		current := &r.lists[len(r.lists)-1]
+		current.items++
+		r.marker = "- "
+		if current.ordered {
+			r.marker = fmt.Sprintf("%d. ", current.items)
+		}
+	case "blockquote":
+		r.quotes++
+	case "pre":
+		r.pre++
+	case "a":
+		if token.Type == StartTagToken {
+			r.hrefs = append(r.hrefs, token.AttrValue("href"))
+		}
+	case "img":
+		if alt := strings.TrimSpace(token.AttrValue("alt")); alt != "" {
+			r.text("[image: " + alt + "]")
+		}
+	case "td", "th":
+		r.text(" ")
+	}
+}
+
+func (r *textRenderer) end(tag string) {
+	switch tag {
+	case "a":
+		if len(r.hrefs) == 0 {
+			return
+		}
+		href := r.hrefs[len(r.hrefs)-1]
+		r.hrefs = r.hrefs[:len(r.hrefs)-1]
+		if href != "" && !strings.HasPrefix(href, "#") {
+			r.para.WriteString(fmt.Sprintf("[%d]", r.footnote(href)))
+		}
+		return
+	}
+
+	if blockTags[tag] {
+		r.flush()
+	}
+
+	switch tag {
+	case "ul", "ol":
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+	case "blockquote":
+		if r.quotes > 0 {
+			r.quotes--
+		}
+	case "pre":
+		if r.pre > 0 {
+			r.pre--
+		}
+	}
+}
+
+func (r *textRenderer) text(s string) {
+	if r.pre > 0 {
+		r.para.WriteString(s)
+		return
+	}
+	for i, field := range strings.Fields(s) {
+		if i > 0 || startsWithSpace(s) {
+			r.space()
+		}
+		r.para.WriteString(field)
+	}
+	if endsWithSpace(s) {
+		r.space()
+	}
+}
+
+func (r *textRenderer) space() {
+	current := r.para.String()
+	if current != "" && !strings.HasSuffix(current, " ") {
+		r.para.WriteString(" ")
+	}
+}
+
+func (r *textRenderer) footnote(href string) int {
+	for i, link := range r.links {
+		if link == href {
+			return i + 1
+		}
+	}
+	r.links = append(r.links, href)
+	return len(r.links)
+}
+
+// flushLine ends the current line without ending the paragraph.
+func (r *textRenderer) flushLine() {
+	r.writeParagraph(true)
+}
+
+// flush ends the current paragraph.
+func (r *textRenderer) flush() {
+	r.writeParagraph(false)
+}
+
+func (r *textRenderer) writeParagraph(continues bool) {
+	text := r.para.String()
+	r.para.Reset()
+
+	if r.pre > 0 {
+		text = strings.Trim(text, "\n")
+	} else {
+		text = strings.TrimSpace(text)
+	}
+	if text == "" {
+		if !continues {
+			r.continued = false
+		}
+		return
+	}
+
+	item := r.marker != ""
+	if !r.continued && !(item && r.lastItem) {
+		r.separate()
+	}
+
+	prefix := r.prefix()
+	first := prefix + r.marker
+	rest := prefix + strings.Repeat(" ", utf8.RuneCountInString(r.marker))
+
+	var lines []string
+	if r.pre > 0 {
+		lines = strings.Split(text, "\n")
+	} else {
+		lines = wrap(text, r.width-utf8.RuneCountInString(first))
+	}
+	for i, line := range lines {
+		if i == 0 {
+			r.out.WriteString(first + line + "\n")
+		} else {
+			r.out.WriteString(rest + line + "\n")
+		}
+	}
+
+	r.continued = continues
+	r.lastItem = item
+	if continues && item {
+		r.marker = strings.Repeat(" ", utf8.RuneCountInString(r.marker))
+	} else {
+		r.marker = ""
+	}
+}
+
+// separate leaves a blank line before the next block.
+func (r *textRenderer) separate() {
+	if r.out.Len() > 0 && !strings.HasSuffix(r.out.String(), "\n\n") {
+		r.out.WriteString("\n")
+	}
+}
+
+func (r *textRenderer) prefix() string {
+	indent := 0
+	if len(r.lists) > 1 {
+		indent = len(r.lists) - 1
+	}
+	return strings.Repeat("> ", r.quotes) + strings.Repeat("  ", indent)
+}
+
+// wrap breaks text into lines of at most width characters, never splitting
+// a word. A width of 0 or less disables wrapping.
+func wrap(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\r\n\f") != s
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\r\n\f") != s
+}
+
+// Plain returns just the readable text of an HTML fragment on a single line,
+// for summaries and listings.
+func Plain(s string) string {
+	var b strings.Builder
+	skipping := ""
+
+	for _, token := range Tokenize(s) {
+		if skipping != "" {
+			if token.Type == EndTagToken && token.Data == skipping {
+				skipping = ""
+			}
+			continue
+		}
+
+		switch token.Type {
+		case TextToken:
+			b.WriteString(token.Data)
+		case StartTagToken, SelfClosingTagToken:
+			if droppedTags[token.Data] && token.Type == StartTagToken {
+				skipping = token.Data
+			} else if blockTags[token.Data] || token.Data == "br" {
+				b.WriteString(" ")
+			}
+		case EndTagToken:
+			if blockTags[token.Data] {
+				b.WriteString(" ")
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,106 @@
+package markup
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{
+			"paragraphs and bullets",
+			`<p>Intro</p><ul><li>one</li><li>two</li></ul><p>After</p>`,
+			0,
+			"Intro\n\n- one\n- two\n\nAfter",
+		},
+		{
+			"nested ordered lists",
+			`<ol><li>first</li><li>second<ol><li>nested</li></ol></li></ol>`,
+			0,
+			"1. first\n2. second\n  1. nested",
+		},
+		{
+			"links become footnotes",
+			`<p>See <a href="https://a.example/">this</a> and <a href="https://b.example/">that</a>, and <a href="https://a.example/">this again</a>.</p>`,
+			0,
+			"See this[1] and that[2], and this again[1].\n\n[1] https://a.example/\n[2] https://b.example/",
+		},
+		{
+			"footnotes survive wrapping",
+			`<p>See <a href="https://a.example/">this</a> and <a href="https://b.example/">that</a>.</p>`,
+			12,
+			"See this[1]\nand that[2].\n\n[1] https://a.example/\n[2] https://b.example/",
+		},
+		{
+			"relative links",
+			`<a href="/rel">rel</a>`,
+			0,
+			"rel[1]\n\n[1] /rel",
+		},
+		{
+			"blockquote",
+			`<blockquote><p>quoted text</p></blockquote>`,
+			12,
+			"> quoted\n> text",
+		},
+		{
+			"line breaks",
+			`<p>line one<br>line two</p>`,
+			0,
+			"line one\nline two",
+		},
+		{
+			"preformatted text keeps its layout",
+			"<pre>  code\n    indented</pre>",
+			4,
+			"  code\n    indented",
+		},
+		{
+			"wrapping",
+			`<p>aaa bbb ccc ddd eee fff</p>`,
+			12,
+			"aaa bbb ccc\nddd eee fff",
+		},
+		{
+			"dropped elements",
+			`<p>a</p><script>x</script><style>p{}</style><p>b</p>`,
+			0,
+			"a\n\nb",
+		},
+		{
+			"whitespace collapses",
+			"<p>  a \n\t b  </p>",
+			0,
+			"a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.input, tt.width); got != tt.want {
+				t.Errorf("Text(%q, %d)\n got %q\nwant %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`<p>Hello</p><p>world &amp; <b>more</b></p>`, "Hello world & more"},
+		{`<ul><li>a</li><li>b</li></ul>`, "a b"},
+		{`text <a href="https://x/">link</a>`, "text link"},
+		{`<script>x</script>only`, "only"},
+		{``, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Plain(tt.input); got != tt.want {
+			t.Errorf("Plain(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
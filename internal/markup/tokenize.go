@@ -0,0 +1,196 @@
+// Package markup sanitizes the HTML found in feeds and renders it as plain
+// terminal text. It is deliberately forgiving: feed markup is often broken,
+// and a best-effort reading beats refusing the post.
+package markup
+
+import (
+	"html"
+	"strings"
+)
+
+type TokenType int
+
+const (
+	TextToken TokenType = iota
+	StartTagToken
+	EndTagToken
+	SelfClosingTagToken
+)
+
+type Attribute struct {
+	Key string
+	Val string
+}
+
+// Token is a piece of HTML. For tags Data is the lower-cased tag name, for
+// text it is the unescaped character data.
+type Token struct {
+	Type TokenType
+	Data string
+	Attr []Attribute
+}
+
+func (t Token) AttrValue(key string) string {
+	for _, attr := range t.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// rawTextElements hold text that must not be scanned for tags.
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// Tokenize splits an HTML fragment into text and tag tokens. Comments,
+// doctypes and processing instructions are dropped.
+func Tokenize(s string) []Token {
+	var tokens []Token
+	text := func(raw string) {
+		if raw != "" {
+			tokens = append(tokens, Token{Type: TextToken, Data: html.UnescapeString(raw)})
+		}
+	}
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			text(s)
+			break
+		}
+		text(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[4+end+3:]
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+		case len(s) > 2 && s[1] == '/' && isLetter(s[2]):
+			name, rest := readName(s[2:])
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, Token{Type: EndTagToken, Data: name})
+			s = rest[end+1:]
+		case len(s) > 1 && isLetter(s[1]):
+			var token Token
+			token, s = readTag(s[1:])
+			tokens = append(tokens, token)
+			if token.Type == StartTagToken && rawTextElements[token.Data] {
+				end := indexFold(s, "</"+token.Data)
+				if end < 0 {
+					end = len(s)
+				}
+				if token.Data == "textarea" || token.Data == "title" {
+					text(s[:end])
+				} else if end > 0 {
+					tokens = append(tokens, Token{Type: TextToken, Data: s[:end]})
+				}
+				s = s[end:]
+			}
+		default:
+			text("<")
+			s = s[1:]
+		}
+	}
+
+	return tokens
+}
+
+func readTag(s string) (Token, string) {
+	name, s := readName(s)
+	token := Token{Type: StartTagToken, Data: name}
+
+	for {
+		s = strings.TrimLeft(s, " \t\r\n\f")
+		switch {
+		case s == "":
+			return token, s
+		case s[0] == '>':
+			return token, s[1:]
+		case strings.HasPrefix(s, "/>"):
+			token.Type = SelfClosingTagToken
+			return token, s[2:]
+		case s[0] == '/':
+			s = s[1:]
+			continue
+		}
+
+		end := strings.IndexAny(s, " \t\r\n\f=/>")
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			end = 1
+		}
+		attr := Attribute{Key: strings.ToLower(s[:end])}
+		s = strings.TrimLeft(s[end:], " \t\r\n\f")
+
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\r\n\f")
+			var value string
+			value, s = readAttrValue(s)
+			attr.Val = html.UnescapeString(value)
+		}
+		token.Attr = append(token.Attr, attr)
+	}
+}
+
+func readAttrValue(s string) (string, string) {
+	if s == "" {
+		return "", s
+	}
+	if quote := s[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(s[1:], quote)
+		if end < 0 {
+			return s[1:], ""
+		}
+		return s[1 : 1+end], s[2+end:]
+	}
+	end := strings.IndexAny(s, " \t\r\n\f>")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+func readName(s string) (string, string) {
+	end := 0
+	for end < len(s) && (isLetter(s[end]) || isDigit(s[end]) || s[end] == '-' || s[end] == ':') {
+		end++
+	}
+	return strings.ToLower(s[:end]), s[end:]
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// indexFold is a case-insensitive strings.Index for an ASCII substr.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
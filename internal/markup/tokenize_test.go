@@ -0,0 +1,91 @@
+package markup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{
+			"text and tags",
+			`<P>Hi <b>there</b></P>`,
+			[]Token{
+				{Type: StartTagToken, Data: "p"},
+				{Type: TextToken, Data: "Hi "},
+				{Type: StartTagToken, Data: "b"},
+				{Type: TextToken, Data: "there"},
+				{Type: EndTagToken, Data: "b"},
+				{Type: EndTagToken, Data: "p"},
+			},
+		},
+		{
+			"attributes",
+			`<a HREF="/x?a=1&amp;b=2" title='t' data-x=bare checked>`,
+			[]Token{
+				{Type: StartTagToken, Data: "a", Attr: []Attribute{
+					{Key: "href", Val: "/x?a=1&b=2"},
+					{Key: "title", Val: "t"},
+					{Key: "data-x", Val: "bare"},
+					{Key: "checked", Val: ""},
+				}},
+			},
+		},
+		{
+			"self-closing",
+			`<br/><img src="a.png" />`,
+			[]Token{
+				{Type: SelfClosingTagToken, Data: "br"},
+				{Type: SelfClosingTagToken, Data: "img", Attr: []Attribute{{Key: "src", Val: "a.png"}}},
+			},
+		},
+		{
+			"entities",
+			`a &lt;b&gt; &amp;amp; &#39;`,
+			[]Token{{Type: TextToken, Data: "a <b> &amp; '"}},
+		},
+		{
+			"raw text is not scanned for tags",
+			`<script>if (a < b) { x = "</p>" }</SCRIPT>after`,
+			[]Token{
+				{Type: StartTagToken, Data: "script"},
+				{Type: TextToken, Data: `if (a < b) { x = "</p>" }`},
+				{Type: EndTagToken, Data: "script"},
+				{Type: TextToken, Data: "after"},
+			},
+		},
+		{
+			"comments and doctypes are dropped",
+			`<!DOCTYPE html><!-- <b>no</b> -->yes<?xml x?>`,
+			[]Token{{Type: TextToken, Data: "yes"}},
+		},
+		{
+			"unterminated comment ends the input",
+			`a<!-- never closed <b>`,
+			[]Token{{Type: TextToken, Data: "a"}},
+		},
+		{
+			"stray less-than is text",
+			`1 < 2 <3`,
+			[]Token{
+				{Type: TextToken, Data: "1 "},
+				{Type: TextToken, Data: "<"},
+				{Type: TextToken, Data: " 2 "},
+				{Type: TextToken, Data: "<"},
+				{Type: TextToken, Data: "3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q)\n got %#v\nwant %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
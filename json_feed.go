@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonFeedVersionPrefix starts the version URL of every JSON Feed release.
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Language    string         `json:"language"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+func parseJSONFeed(data []byte) (*ParsedFeed, error) {
+	var feed JSONFeed
+	err := json.Unmarshal(data, &feed)
+	if err != nil {
+		return nil, err
+	}
+	// Any JSON document decodes into JSONFeed; the version is what tells a
+	// feed apart from some other API response.
+	if !strings.HasPrefix(feed.Version, jsonFeedVersionPrefix) {
+		return nil, fmt.Errorf("not a JSON Feed: unknown version %q", feed.Version)
+	}
+
+	parsed := &ParsedFeed{
+		Format:      "json",
+		Title:       feed.Title,
+		Link:        feed.HomePageURL,
+		Description: feed.Description,
+		Icon:        firstNonEmpty(feed.Icon, feed.Favicon),
+		Language:    feed.Language,
+	}
+
+	for _, item := range feed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		parsedItem := FeedItem{
+			GUID:        jsonFeedID(item.ID),
+			Title:       item.Title,
+			Link:        link,
+			Description: item.Summary,
+			Content:     content,
+			PubDate:     pubDate,
+		}
+		for _, attachment := range item.Attachments {
+			parsedItem.addEnclosure(Enclosure{
+				URL:      attachment.URL,
+				Type:     attachment.MimeType,
+				Length:   attachment.SizeInBytes,
+				Duration: int(attachment.DurationInSeconds),
+			})
+		}
+
+		parsed.Items = append(parsed.Items, parsedItem)
+	}
+
+	return parsed, nil
+}
+
+// jsonFeedID accepts ids published as numbers as well as the strings the
+// spec requires.
+func jsonFeedID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func isJSONFeed(contentType string, data []byte) bool {
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	trimmed := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	return strings.HasPrefix(trimmed, "{")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *ParsedFeed
+	}{
+		{
+			"feed.json",
+			&ParsedFeed{
+				Format:      "json",
+				Title:       "Example Microblog",
+				Link:        "https://micro.example.com/",
+				Description: "Short posts",
+				Icon:        "https://micro.example.com/favicon.png",
+				Language:    "en",
+				Items: []FeedItem{
+					{
+						GUID:        "https://micro.example.com/2024/04/01/hello",
+						Title:       "Hello",
+						Link:        "https://micro.example.com/2024/04/01/hello",
+						Description: "A greeting",
+						Content:     `<p>Hello, <a href="https://example.org/">world</a>.</p>`,
+						PubDate:     "2024-04-01T10:00:00-05:00",
+					},
+					{
+						GUID:    "2",
+						Link:    "https://elsewhere.example.org/post",
+						Content: "Just text.",
+						PubDate: "2024-04-02T08:00:00Z",
+						Enclosures: []Enclosure{
+							{URL: "https://micro.example.com/audio/2.m4a", Type: "audio/x-m4a", Length: 89970236, Duration: 6629},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := parseJSONFeed(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseJSONFeed: %v", err)
+			}
+			checkParsedFeed(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseJSONFeedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"api response", `{"data":[1,2,3]}`, `not a JSON Feed: unknown version ""`},
+		{"wordpress rest api", `{"id":7,"title":{"rendered":"About"},"link":"https://example.com/about/"}`, "cannot unmarshal"},
+		{"other version url", `{"version":"https://example.com/version/1","items":[]}`, "not a JSON Feed"},
+		{"bare version number", `{"version":"1.1","items":[]}`, `unknown version "1.1"`},
+		{"invalid json", `{"version":`, "unexpected end of JSON input"},
+		{"array", `[{"version":"https://jsonfeed.org/version/1.1"}]`, "cannot unmarshal array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSONFeed([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseJSONFeed(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
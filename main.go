@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/RafaelTauschek/internal/config"
 	"github.com/RafaelTauschek/internal/database"
@@ -13,8 +16,9 @@ import (
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db     *database.Queries
+	cfg    *config.Config
+	output outputFormat
 }
 
 func main() {
@@ -37,36 +41,55 @@ func main() {
 	}
 
 	cmds := &commands{
-		commands: make(map[string]func(*state, command) error),
+		commands: make(map[string]func(context.Context, *state, command) error),
+		lists:    make(map[string]bool),
 	}
 
 	cmds.register("login", handlerLogin)
 	cmds.register("register", handlerRegister)
 	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerUsers)
+	cmds.registerList("users", handlerUsers)
 	cmds.register("agg", handlerAggregate)
 	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	cmds.register("feeds", handlerFeeds)
+	cmds.registerList("feeds", handlerFeeds)
+	cmds.registerList("feedstatus", handlerFeedStatus)
 	cmds.register("follow", middlewareLoggedIn(handlerFollow))
-	cmds.register("following", middlewareLoggedIn(handlerFollowing))
+	cmds.registerList("following", middlewareLoggedIn(handlerFollowing))
 	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
+	cmds.registerList("browse", middlewareLoggedIn(handlerBrowse))
+	cmds.register("read", middlewareLoggedIn(handlerRead))
+	cmds.register("unread", middlewareLoggedIn(handlerUnread))
+	cmds.register("star", middlewareLoggedIn(handlerStar))
+	cmds.register("unstar", middlewareLoggedIn(handlerUnstar))
+	cmds.registerList("starred", middlewareLoggedIn(handlerStarred))
+	cmds.registerList("search", middlewareLoggedIn(handlerSearch))
+	cmds.register("download", handlerDownload)
+	cmds.register("import", middlewareLoggedIn(handlerImport))
+	cmds.register("export", handlerExport)
+
+	output, args, err := extractOutputFlag(os.Args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.output = output
 
-	args := os.Args
 	if len(args) < 2 {
 		fmt.Println("Not enough arguments provided")
 		return
 	}
 
-	cmdName := os.Args[1]
-	arg := os.Args[2:]
+	cmdName := args[1]
+	arg := args[2:]
 
 	cmd := command{
 		name:      cmdName,
 		arguments: arg,
 	}
 
-	err = cmds.run(s, cmd)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = cmds.run(ctx, s, cmd)
 	if err != nil {
 		log.Fatal(err)
 	}
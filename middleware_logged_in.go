@@ -6,12 +6,12 @@ import (
 	"github.com/RafaelTauschek/internal/database"
 )
 
-func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
-	return func(s *state, cmd command) error {
-		user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+func middlewareLoggedIn(handler func(ctx context.Context, s *state, cmd command, user database.User) error) func(context.Context, *state, command) error {
+	return func(ctx context.Context, s *state, cmd command) error {
+		user, err := s.db.GetUser(ctx, s.cfg.CurrentUser)
 		if err != nil {
 			return err
 		}
-		return handler(s, cmd, user)
+		return handler(ctx, s, cmd, user)
 	}
 }
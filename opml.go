@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+type OPMLHead struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+type OPMLBody struct {
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// opmlSubscription is a feed outline together with the folder it was filed
+// under, nested folders being joined with "/".
+type opmlSubscription struct {
+	Name    string
+	URL     string
+	SiteURL string
+	Folder  string
+}
+
+func parseOPML(r io.Reader) ([]opmlSubscription, error) {
+	var doc OPML
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []opmlSubscription
+	var walk func(outlines []OPMLOutline, folder string)
+	walk = func(outlines []OPMLOutline, folder string) {
+		for _, outline := range outlines {
+			name := strings.TrimSpace(outline.Title)
+			if name == "" {
+				name = strings.TrimSpace(outline.Text)
+			}
+
+			url := strings.TrimSpace(outline.XMLURL)
+			if url == "" {
+				// Outlines without a feed URL are folders.
+				child := name
+				if folder != "" && name != "" {
+					child = folder + "/" + name
+				} else if name == "" {
+					child = folder
+				}
+				walk(outline.Outlines, child)
+				continue
+			}
+
+			if name == "" {
+				name = url
+			}
+			subscriptions = append(subscriptions, opmlSubscription{
+				Name:    name,
+				URL:     url,
+				SiteURL: strings.TrimSpace(outline.HTMLURL),
+				Folder:  folder,
+			})
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	if len(subscriptions) == 0 {
+		return nil, errors.New("no feeds found in OPML file")
+	}
+
+	return subscriptions, nil
+}
+
+// opmlOutlines builds the outline tree for subscriptions, filing each feed
+// under nested folder outlines. Folders come before feeds, both sorted by
+// name.
+func opmlOutlines(subscriptions []opmlSubscription) []OPMLOutline {
+	type folder struct {
+		outline *OPMLOutline
+		folders map[string]*folder
+	}
+	root := &folder{outline: &OPMLOutline{}, folders: map[string]*folder{}}
+
+	for _, sub := range subscriptions {
+		current := root
+		for _, name := range strings.Split(sub.Folder, "/") {
+			if name == "" {
+				continue
+			}
+			child, ok := current.folders[name]
+			if !ok {
+				child = &folder{outline: &OPMLOutline{Text: name, Title: name}, folders: map[string]*folder{}}
+				current.folders[name] = child
+			}
+			current = child
+		}
+		current.outline.Outlines = append(current.outline.Outlines, OPMLOutline{
+			Text:    sub.Name,
+			Title:   sub.Name,
+			Type:    "rss",
+			XMLURL:  sub.URL,
+			HTMLURL: sub.SiteURL,
+		})
+	}
+
+	var build func(f *folder) []OPMLOutline
+	build = func(f *folder) []OPMLOutline {
+		names := make([]string, 0, len(f.folders))
+		for name := range f.folders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		feeds := f.outline.Outlines
+		sort.SliceStable(feeds, func(i, j int) bool {
+			return strings.ToLower(feeds[i].Text) < strings.ToLower(feeds[j].Text)
+		})
+
+		outlines := make([]OPMLOutline, 0, len(names)+len(feeds))
+		for _, name := range names {
+			child := f.folders[name]
+			child.outline.Outlines = build(child)
+			outlines = append(outlines, *child.outline)
+		}
+		return append(outlines, feeds...)
+	}
+
+	return build(root)
+}
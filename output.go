@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type outputFormat string
+
+const (
+	outputText outputFormat = "text"
+	outputJSON outputFormat = "json"
+	outputCSV  outputFormat = "csv"
+	outputTSV  outputFormat = "tsv"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(s)); format {
+	case outputText, outputJSON, outputCSV, outputTSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, use text, json, csv or tsv", s)
+	}
+}
+
+// extractOutputFlag pulls the global --output option out of the command line
+// wherever it appears, so the per-command flag sets never see it.
+func extractOutputFlag(args []string) (outputFormat, []string, error) {
+	format := outputText
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--output" && name != "-output" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return "", nil, fmt.Errorf("%s needs a value: text, json, csv or tsv", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		var err error
+		format, err = parseOutputFormat(value)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return format, rest, nil
+}
+
+// table is what list commands hand to renderTable: named columns, and one row
+// of values per listed item. The column names are the field names of the
+// json, csv and tsv output and must not change.
+type table struct {
+	columns []string
+	rows    [][]any
+}
+
+func newTable(columns ...string) *table {
+	return &table{columns: columns}
+}
+
+func (t *table) add(values ...any) {
+	t.rows = append(t.rows, values)
+}
+
+// renderTable writes t to stdout in the format chosen with --output. Text
+// output is left to the command, which is called back once per row.
+func renderTable(s *state, t *table, text func(row int)) error {
+	switch s.output {
+	case outputJSON:
+		return writeJSON(os.Stdout, t)
+	case outputCSV:
+		return writeCSV(os.Stdout, t)
+	case outputTSV:
+		return writeTSV(os.Stdout, t)
+	default:
+		for i := range t.rows {
+			text(i)
+		}
+		return nil
+	}
+}
+
+func writeJSON(w io.Writer, t *table) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range t.rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		// Written by hand to keep the columns in order.
+		for j, column := range t.columns {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(column)
+			value, err := json.Marshal(cellValue(row[j]))
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(value)
+		}
+		buf.WriteString("}")
+	}
+	if len(t.rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeCSV(w io.Writer, t *table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.columns); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = cellText(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTSV writes tab separated values without quoting; tabs and line breaks
+// inside values are turned into spaces instead.
+func writeTSV(w io.Writer, t *table) error {
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(t.columns, "\t"))
+	buf.WriteString("\n")
+	for _, row := range t.rows {
+		for i, value := range row {
+			if i > 0 {
+				buf.WriteString("\t")
+			}
+			buf.WriteString(clean.Replace(cellText(value)))
+		}
+		buf.WriteString("\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// cellValue unwraps nullable database values, so that missing values become
+// null, and formats times as RFC 3339.
+func cellValue(value any) any {
+	switch v := value.(type) {
+	case sql.NullString:
+		if !v.Valid {
+			return nil
+		}
+		return v.String
+	case sql.NullTime:
+		if !v.Valid {
+			return nil
+		}
+		return v.Time.UTC().Format(time.RFC3339)
+	case sql.NullInt32:
+		if !v.Valid {
+			return nil
+		}
+		return v.Int32
+	case sql.NullInt64:
+		if !v.Valid {
+			return nil
+		}
+		return v.Int64
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return value
+	}
+}
+
+func cellText(value any) string {
+	switch v := cellValue(value).(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
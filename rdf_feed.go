@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/xml"
+)
+
+// RDFFeed is an RSS 1.0 document. Unlike RSS 2.0 its items are siblings of
+// the channel rather than children of it.
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	} `xml:"channel"`
+	Image struct {
+		URL string `xml:"url"`
+	} `xml:"image"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+func parseRDF(data []byte) (*ParsedFeed, error) {
+	var feed RDFFeed
+	err := xml.Unmarshal(data, &feed)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedFeed{
+		Format:      "rdf",
+		Title:       feed.Channel.Title,
+		Link:        feed.Channel.Link,
+		Description: feed.Channel.Description,
+		Icon:        feed.Image.URL,
+		Language:    feed.Channel.Language,
+	}
+
+	for _, item := range feed.Item {
+		parsed.Items = append(parsed.Items, FeedItem{
+			GUID:        item.About,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     item.Content,
+			PubDate:     item.Date,
+		})
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestParseRDF(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *ParsedFeed
+	}{
+		{
+			"rdf.xml",
+			&ParsedFeed{
+				Format:      "rdf",
+				Title:       "Example Journal",
+				Link:        "https://journal.example.edu/",
+				Description: "Latest articles",
+				Icon:        "https://journal.example.edu/logo.gif",
+				Language:    "de",
+				Items: []FeedItem{
+					{
+						GUID:        "https://journal.example.edu/a/1",
+						Title:       "On Caching",
+						Link:        "https://journal.example.edu/a/1",
+						Description: "An abstract.",
+						Content:     "<p>Full text.</p>",
+						PubDate:     "2024-01-15T09:00:00+01:00",
+					},
+					{
+						GUID:    "https://journal.example.edu/a/2",
+						Title:   "On Naming",
+						Link:    "https://journal.example.edu/a/2",
+						PubDate: "2024-01-22",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := parseRDF(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseRDF: %v", err)
+			}
+			checkParsedFeed(t, got, tt.want)
+		})
+	}
+}
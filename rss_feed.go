@@ -1,62 +1,135 @@
 package main
 
 import (
-	"context"
 	"encoding/xml"
-	"html"
-	"io"
-	"net/http"
+	"strings"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		rssExtensions
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"language"`
+		// itunes:image comes first so that it is not taken for the
+		// channel's own <image>.
+		ITunesImage struct {
+			Href string `xml:"href,attr"`
+		} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Image struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Item []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
+	rssExtensions
+	GUID        string `xml:"guid"`
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
 	PubDate     string `xml:"pubDate"`
+
+	Enclosures     []RSSEnclosure `xml:"enclosure"`
+	MediaContent   []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups    []MediaGroup   `xml:"http://search.yahoo.com/mrss/ group"`
+	ITunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// rssExtensions swallows the extension elements that share a local name with
+// an RSS element, such as atom:link, itunes:title or media:description.
+// encoding/xml matches an unqualified tag in any namespace, so these come
+// first to keep them from overwriting the channel's or item's own fields.
+type rssExtensions struct {
+	AtomLinks         []struct{} `xml:"http://www.w3.org/2005/Atom link"`
+	ITunesTitles      []struct{} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	MediaTitles       []struct{} `xml:"http://search.yahoo.com/mrss/ title"`
+	MediaDescriptions []struct{} `xml:"http://search.yahoo.com/mrss/ description"`
+	DCTitles          []struct{} `xml:"http://purl.org/dc/elements/1.1/ title"`
+	DCDescriptions    []struct{} `xml:"http://purl.org/dc/elements/1.1/ description"`
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, err
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type MediaGroup struct {
+	Content []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+func (item RSSItem) enclosures() []Enclosure {
+	parsed := FeedItem{}
+	for _, enclosure := range item.Enclosures {
+		parsed.addEnclosure(Enclosure{
+			URL:    enclosure.URL,
+			Type:   enclosure.Type,
+			Length: parseLength(enclosure.Length),
+		})
 	}
-	req.Header.Add("User-Agent", "gator")
-	client := &http.Client{}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	media := item.MediaContent
+	for _, group := range item.MediaGroups {
+		media = append(media, group.Content...)
+	}
+	for _, content := range media {
+		parsed.addEnclosure(Enclosure{
+			URL:      content.URL,
+			Type:     content.Type,
+			Length:   parseLength(content.FileSize),
+			Duration: parseMediaDuration(content.Duration),
+		})
 	}
-	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	// itunes:duration describes the episode, which is the item's enclosure.
+	duration := parseMediaDuration(item.ITunesDuration)
+	for i := range parsed.Enclosures {
+		if parsed.Enclosures[i].Duration == 0 {
+			parsed.Enclosures[i].Duration = duration
+		}
 	}
 
+	return parsed.Enclosures
+}
+
+func parseRSS(data []byte) (*ParsedFeed, error) {
 	var feed RSSFeed
-	err = xml.Unmarshal(data, &feed)
+	err := xml.Unmarshal(data, &feed)
 	if err != nil {
 		return nil, err
 	}
 
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	parsed := &ParsedFeed{
+		Format:      "rss",
+		Title:       feed.Channel.Title,
+		Link:        feed.Channel.Link,
+		Description: feed.Channel.Description,
+		Icon:        firstNonEmpty(feed.Channel.Image.URL, feed.Channel.ITunesImage.Href),
+		Language:    feed.Channel.Language,
+	}
 
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	for _, item := range feed.Channel.Item {
+		parsed.Items = append(parsed.Items, FeedItem{
+			GUID:        strings.TrimSpace(item.GUID),
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     item.Content,
+			PubDate:     item.PubDate,
+			Enclosures:  item.enclosures(),
+		})
 	}
 
-	return &feed, nil
+	return parsed, nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestParseRSS(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    *ParsedFeed
+	}{
+		{
+			"rss.xml",
+			&ParsedFeed{
+				Format:      "rss",
+				Title:       "Example News",
+				Link:        "https://news.example.com/",
+				Description: "Headlines &amp; more",
+				Icon:        "https://news.example.com/logo.png",
+				Language:    "en-gb",
+				Items: []FeedItem{
+					{
+						GUID:        "news-1",
+						Title:       "First story",
+						Link:        "https://news.example.com/first",
+						Description: "<p>Teaser</p>",
+						Content:     "<p>The <b>whole</b> story.</p>",
+						PubDate:     "Tue, 05 Mar 2024 10:00:00 GMT",
+					},
+					{
+						Title:       "No guid",
+						Link:        "https://news.example.com/second",
+						Description: "Plain text",
+						PubDate:     "Wed, 06 Mar 2024 11:30:00 +0100",
+					},
+				},
+			},
+		},
+		{
+			// itunes:, media: and dc: elements share local names with RSS
+			// ones and must not replace them.
+			"rss_podcast.xml",
+			&ParsedFeed{
+				Format:      "rss",
+				Title:       "The Example Show",
+				Link:        "https://show.example.net/",
+				Description: "Weekly conversations.",
+				Icon:        "https://show.example.net/cover.jpg",
+				Language:    "en",
+				Items: []FeedItem{
+					{
+						GUID:        "show-42",
+						Title:       "Episode 42: Deadlines",
+						Link:        "https://show.example.net/42",
+						Description: "We talk about deadlines.",
+						PubDate:     "Mon, 01 Apr 2024 06:00:00 +0000",
+						Enclosures: []Enclosure{
+							{URL: "https://cdn.example.net/42.mp3", Type: "audio/mpeg", Length: 52428800, Duration: 3723},
+						},
+					},
+					{
+						GUID:    "https://show.example.net/41",
+						Title:   "Episode 41: Video",
+						Link:    "https://show.example.net/41",
+						PubDate: "Mon, 25 Mar 2024 06:00:00 +0000",
+						Enclosures: []Enclosure{
+							{URL: "https://cdn.example.net/41.mp4", Type: "video/mp4", Length: 2000, Duration: 120},
+							{URL: "https://cdn.example.net/41.webm", Type: "video/webm"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := parseRSS(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseRSS: %v", err)
+			}
+			checkParsedFeed(t, got, tt.want)
+		})
+	}
+}
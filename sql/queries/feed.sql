@@ -21,11 +21,44 @@ SELECT * FROM feeds WHERE url = $1;
 
 -- name: MarkFeedFetched :one
 UPDATE feeds
-SET last_fechted_at = $1, updated_at = $2
-WHERE id = $3
+SET last_fechted_at = $1, updated_at = $2, etag = $3, last_modified = $4,
+    last_fetch_status = $5, last_fetch_error = NULL, consecutive_failures = 0, next_fetch_at = NULL,
+    locked_by = NULL, locked_until = NULL
+WHERE id = $6 AND locked_by = $7
 RETURNING *;
 
--- name: GetNextFeedToFetch :one
-SELECT * FROM feeds
-ORDER BY last_fechted_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+-- name: MarkFeedFetchFailed :one
+UPDATE feeds
+SET last_fechted_at = @last_fechted_at, updated_at = @updated_at,
+    last_fetch_status = @last_fetch_status, last_fetch_error = @last_fetch_error,
+    consecutive_failures = consecutive_failures + 1,
+    next_fetch_at = now() + @backoff_seconds::float8 * interval '1 second',
+    locked_by = NULL, locked_until = NULL
+WHERE id = @id AND locked_by = @locked_by
+RETURNING *;
+
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET locked_by = @locked_by, locked_until = now() + @lease_seconds::float8 * interval '1 second'
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE (feeds.locked_until IS NULL OR feeds.locked_until < now())
+    AND (feeds.next_fetch_at IS NULL OR feeds.next_fetch_at <= now())
+    ORDER BY last_fechted_at ASC NULLS FIRST
+    LIMIT @max_feeds
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET locked_by = NULL, locked_until = NULL
+WHERE id = $1 AND locked_by = $2;
+
+-- name: SetFeedSiteLinkIfMissing :exec
+UPDATE feeds SET site_link = $1 WHERE id = $2 AND site_link IS NULL;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET site_link = $1, channel_title = $2, description = $3, icon_url = $4, language = $5
+WHERE id = $6;
\ No newline at end of file
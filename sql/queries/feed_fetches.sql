@@ -0,0 +1,24 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches(id, feed_id, fetched_at, duration_ms, status_code, bytes, new_posts, updated_posts, error)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY fetched_at DESC
+LIMIT $2;
+
+-- name: GetFeedFetchStats :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.consecutive_failures, feeds.next_fetch_at, feeds.last_fetch_error,
+    COUNT(feed_fetches.id)::int AS attempts,
+    (COUNT(feed_fetches.id) FILTER (WHERE feed_fetches.error IS NULL))::int AS successes,
+    COALESCE(AVG(feed_fetches.duration_ms), 0)::float8 AS avg_duration_ms,
+    COALESCE(SUM(feed_fetches.new_posts), 0)::int AS new_posts
+FROM feeds
+LEFT JOIN feed_fetches ON feed_fetches.feed_id = feeds.id
+WHERE sqlc.narg(feed_id)::uuid IS NULL OR feeds.id = sqlc.narg(feed_id)::uuid
+GROUP BY feeds.id
+ORDER BY feeds.name;
+
+-- name: DeleteFeedFetches :exec
+DELETE FROM feed_fetches;
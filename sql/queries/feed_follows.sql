@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows(id, created_at, updated_at, user_id, feed_id)
-    VALUES ($1, NOW(), NOW(), $2, $3)
+    INSERT INTO feed_follows(id, created_at, updated_at, user_id, feed_id, folder)
+    VALUES ($1, NOW(), NOW(), $2, $3, $4)
     RETURNING *
 )
 SELECT inserted_feed_follow.*, users.name AS user_name, feeds.name AS feed_name
@@ -14,7 +14,7 @@ INNER JOIN feeds ON inserted_feed_follow.feed_id = feeds.id;
 DELETE FROM feed_follows;
 
 -- name: GetFeedFollowForUser :many
-SELECT feed_follows.*, users.name AS user_name, feeds.name AS feeds_name
+SELECT feed_follows.*, users.name AS user_name, feeds.name AS feeds_name, feeds.url AS feed_url, feeds.site_link AS feed_site_link
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds on feed_follows.feed_id = feeds.id
@@ -1,16 +1,63 @@
--- name: CreatePost :one
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING *;
+-- name: AdoptLegacyPost :exec
+-- Posts stored before guids were tracked were keyed on their url. Give such a
+-- post the item's real guid, so that the upsert which follows updates it
+-- instead of inserting a duplicate.
+UPDATE posts
+SET guid = @guid
+WHERE posts.feed_id = @feed_id AND posts.url = @url AND posts.guid = posts.url
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = @feed_id AND existing.guid = @guid
+);
 
--- name: GetPostsForUser :many
-SELECT posts.*, feeds.name AS feed_name FROM posts
+-- name: UpsertPost :one
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_inferred, guid, content_hash, content)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    published_at = CASE WHEN EXCLUDED.published_at_inferred THEN posts.published_at ELSE EXCLUDED.published_at END,
+    published_at_inferred = posts.published_at_inferred AND EXCLUDED.published_at_inferred,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = EXCLUDED.updated_at
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, published_at_inferred, guid, content_hash, content, (xmax = 0)::boolean AS inserted;
+
+-- name: BrowsePostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_inferred, posts.guid, posts.content_hash, posts.content, feeds.name AS feed_name, post_reads.read_at, post_stars.starred_at FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 JOIN feeds ON posts.feed_id = feeds.id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+LEFT JOIN post_stars ON post_stars.post_id = posts.id AND post_stars.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = @user_id
+AND (@include_read::boolean OR post_reads.post_id IS NULL)
+AND (NOT @starred_only::boolean OR post_stars.post_id IS NOT NULL)
+AND (sqlc.narg('feed_id')::uuid IS NULL OR posts.feed_id = sqlc.narg('feed_id'))
+AND (sqlc.narg('since')::timestamp IS NULL OR posts.published_at >= sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR posts.published_at < sqlc.narg('until'))
+ORDER BY
+    CASE WHEN @sort_by::text = 'feed' THEN feeds.name END ASC,
+    CASE WHEN @sort_by::text = 'fetched' THEN posts.created_at END DESC,
+    posts.published_at DESC NULLS LAST,
+    posts.id
+LIMIT @max_posts OFFSET @skip_posts;
 
 
 -- name: DeletePosts :exec
-DELETE FROM posts;
\ No newline at end of file
+DELETE FROM posts;
+
+-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, feeds.name AS feed_name,
+    ts_rank(posts.search_vector, query)::float8 AS rank,
+    ts_headline('english', coalesce(posts.content, posts.description, posts.title), query,
+        'StartSel=**, StopSel=**, MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON posts.feed_id = feeds.id
+CROSS JOIN websearch_to_tsquery('english', @query) AS query
+WHERE feed_follows.user_id = @user_id
+AND posts.search_vector @@ query
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT @max_results;
@@ -0,0 +1,27 @@
+-- name: MarkPostRead :execrows
+-- Re-reading keeps the original read_at, but still counts as a row so that
+-- zero rows means the post does not exist.
+INSERT INTO post_reads(user_id, post_id, read_at)
+SELECT @user_id, posts.id, @read_at
+FROM posts
+WHERE posts.id = @post_id
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = post_reads.read_at;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads(user_id, post_id, read_at)
+SELECT @user_id, posts.id, @read_at
+FROM posts
+WHERE posts.feed_id = @feed_id
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads(user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, @read_at
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = @user_id
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads WHERE user_id = $1 AND post_id = $2;
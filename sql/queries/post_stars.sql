@@ -0,0 +1,18 @@
+-- name: StarPost :execrows
+INSERT INTO post_stars(user_id, post_id, starred_at)
+SELECT @user_id, posts.id, @starred_at
+FROM posts
+WHERE posts.id = @post_id
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET starred_at = post_stars.starred_at;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.published_at_inferred, posts.guid, posts.content_hash, posts.content, feeds.name AS feed_name, post_stars.starred_at FROM post_stars
+JOIN posts ON posts.id = post_stars.post_id
+JOIN feeds ON posts.feed_id = feeds.id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.starred_at DESC
+LIMIT $2;
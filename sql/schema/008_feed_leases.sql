@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD locked_by TEXT,
+ADD locked_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN locked_by,
+DROP COLUMN locked_until;
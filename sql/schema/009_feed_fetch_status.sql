@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD last_fetch_status INTEGER,
+ADD last_fetch_error TEXT,
+ADD consecutive_failures INTEGER NOT NULL DEFAULT 0,
+ADD next_fetch_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_fetch_status,
+DROP COLUMN last_fetch_error,
+DROP COLUMN consecutive_failures,
+DROP COLUMN next_fetch_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE feed_fetches(
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL,
+    fetched_at TIMESTAMP NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    status_code INTEGER,
+    bytes BIGINT NOT NULL,
+    new_posts INTEGER NOT NULL,
+    error TEXT,
+    FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE INDEX feed_fetches_feed_id_fetched_at_idx ON feed_fetches(feed_id, fetched_at DESC);
+
+-- +goose Down
+DROP TABLE feed_fetches;
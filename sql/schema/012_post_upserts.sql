@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts
+ADD content_hash TEXT;
+
+ALTER TABLE feed_fetches
+ADD updated_posts INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN content_hash;
+
+ALTER TABLE feed_fetches
+DROP COLUMN updated_posts;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE enclosures(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    mime_type TEXT,
+    length BIGINT,
+    duration_seconds INTEGER,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    UNIQUE(post_id, url)
+);
+
+-- +goose Down
+DROP TABLE enclosures;
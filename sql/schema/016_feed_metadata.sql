@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD site_link TEXT,
+ADD channel_title TEXT,
+ADD description TEXT,
+ADD icon_url TEXT,
+ADD language TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN site_link,
+DROP COLUMN channel_title,
+DROP COLUMN description,
+DROP COLUMN icon_url,
+DROP COLUMN language;
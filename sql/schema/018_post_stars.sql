@@ -0,0 +1,14 @@
+-- +goose Up
+-- post_id deliberately has no ON DELETE CASCADE: a starred post cannot be
+-- deleted until it is unstarred, so cleanup has to leave it alone.
+CREATE TABLE post_stars(
+    user_id UUID NOT NULL,
+    post_id UUID NOT NULL,
+    starred_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id),
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE post_stars;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ALTER COLUMN locked_until TYPE TIMESTAMPTZ,
+ALTER COLUMN next_fetch_at TYPE TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE feeds
+ALTER COLUMN locked_until TYPE TIMESTAMP,
+ALTER COLUMN next_fetch_at TYPE TIMESTAMP;